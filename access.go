@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/MaulanaR/zai/auth"
+	"github.com/MaulanaR/zai/session"
+	"github.com/MaulanaR/zai/zahir"
+)
+
+// authenticate memverifikasi pemanggil. Token diambil dari header Authorization, body, lalu cookie session
+// login; slug dari header slug lalu dari body (kosong berarti SLUG dari env). Tanpa token, kredensial env
+// hanya dipakai jika ALLOW_ENV_CREDENTIALS aktif
+func (bot *ChatBot) authenticate(r *http.Request, bearerToken, slug string) (*auth.Principal, error) {
+	if h, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && strings.TrimSpace(h) != "" {
+		bearerToken = strings.TrimSpace(h)
+	}
+	if h := r.Header.Get("slug"); h != "" {
+		slug = h
+	}
+	if c, err := r.Cookie(LoginCookie); bearerToken == "" && err == nil {
+		login, err := bot.logins.Get(r.Context(), c.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bot.verifier.Verify(r.Context(), login.BearerToken, login.Slug)
+	}
+	if bearerToken == "" {
+		if !AllowEnvCredentials || BearerToken == "" {
+			return nil, auth.ErrUnauthenticated
+		}
+		if slug != "" && slug != Slug {
+			// kredensial env hanya berlaku untuk slug env
+			return nil, auth.ErrUnauthenticated
+		}
+		return &auth.Principal{BearerToken: BearerToken, Slug: Slug, Role: EnvCredentialsRole}, nil
+	}
+	if slug == "" {
+		slug = Slug
+	}
+	return bot.verifier.Verify(r.Context(), bearerToken, slug)
+}
+
+// introspect memeriksa token ke Zahir, 401/403 berarti token tidak berlaku untuk slug tersebut
+func (bot *ChatBot) introspect(ctx context.Context, bearerToken, slug string) error {
+	err := bot.zahirClient(bearerToken, slug).Ping(ctx)
+	var apiErr *zahir.APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+		return auth.ErrInvalidToken
+	}
+	return err
+}
+
+// writeAuthError response 401 untuk token kosong/tidak valid, 502 jika Zahir tidak bisa dihubungi
+func writeAuthError(w http.ResponseWriter, err error) {
+	status := http.StatusBadGateway
+	if errors.Is(err, auth.ErrUnauthenticated) || errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrLoginNotFound) {
+		status = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", `Bearer realm="zai"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ZahirResponse{Status: "error", Message: err.Error()})
+}
+
+// forbidden jawaban untuk aksi yang tidak boleh dijalankan role pemanggil
+func forbidden(p *auth.Principal, what string) *ZahirResponse {
+	role := auth.Role("anonim")
+	if p != nil {
+		role = p.Role
+	}
+	return &ZahirResponse{Status: "error", Message: fmt.Sprintf("Akses ditolak: role %s tidak boleh %s", role, what)}
+}
+
+// sessionKey id session milik principal. session_id dari client diberi prefix kredensial supaya
+// pemanggil lain tidak bisa memakai history dan aksi tertunda session tersebut
+func sessionKey(p *auth.Principal, sessionID string) string {
+	key := session.KeyFromCredential(p.BearerToken, p.Slug)
+	if sessionID == "" {
+		return key
+	}
+	return key + ":" + sessionID
+}
@@ -0,0 +1,188 @@
+// Package action menyimpan aksi tulis (POST/PATCH/PUT/DELETE) yang menunggu konfirmasi user.
+// Setiap aksi punya ID, payload, diff sebelum/sesudah dan waktu kedaluwarsa; aksi baru
+// dijalankan setelah client mengirim konfirmasi dengan ID tersebut.
+package action
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("aksi tidak ditemukan atau sudah diproses")
+	ErrExpired  = errors.New("aksi sudah kedaluwarsa, silakan ulangi permintaan")
+)
+
+// Change perubahan satu field, Before kosong untuk data baru dan After kosong untuk hapus
+type Change struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// Action satu aksi tulis yang menunggu konfirmasi
+type Action struct {
+	ID        string    `json:"pending_action_id"`
+	Method    string    `json:"method"`
+	Endpoint  string    `json:"endpoint"`
+	RecordID  string    `json:"record_id,omitempty"` // ID data untuk PATCH/PUT/DELETE
+	Summary   string    `json:"summary"`
+	Payload   any       `json:"payload,omitempty"`
+	Diff      []Change  `json:"diff"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	SessionID string `json:"-"`
+	Form      string `json:"-"` // ID form input untuk diisi ulang jika Zahir menolak payload
+	Data      any    `json:"-"` // data tambahan untuk eksekusi, misal draft faktur
+	createdAt time.Time
+}
+
+// Destructive true untuk aksi yang menghapus data, wajib dikonfirmasi secara eksplisit
+func (a *Action) Destructive() bool {
+	return a.Method == http.MethodDelete
+}
+
+// Store penyimpanan aksi di memory
+type Store struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	actions map[string]*Action
+}
+
+// NewStore membuat store dengan masa berlaku ttl untuk setiap aksi
+func NewStore(ttl time.Duration) *Store {
+	return &Store{ttl: ttl, actions: map[string]*Action{}}
+}
+
+// Add menyimpan aksi baru, ID dan ExpiresAt diisi oleh store
+func (s *Store) Add(a *Action) *Action {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+
+	a.ID = newID()
+	a.createdAt = time.Now()
+	a.ExpiresAt = a.createdAt.Add(s.ttl)
+	s.actions[a.ID] = a
+	return a
+}
+
+// Take mengambil dan menghapus aksi milik session, dipakai saat konfirmasi
+func (s *Store) Take(id, sessionID string) (*Action, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.actions[id]
+	if !ok || a.SessionID != sessionID {
+		return nil, ErrNotFound
+	}
+	delete(s.actions, id)
+	if time.Now().After(a.ExpiresAt) {
+		return nil, ErrExpired
+	}
+	return a, nil
+}
+
+// Cancel membatalkan aksi milik session
+func (s *Store) Cancel(id, sessionID string) (*Action, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.actions[id]
+	if !ok || a.SessionID != sessionID {
+		return nil, ErrNotFound
+	}
+	delete(s.actions, id)
+	return a, nil
+}
+
+// Latest aksi terbaru milik session yang belum kedaluwarsa, dipakai untuk balasan "ya"/"batal" lewat chat
+func (s *Store) Latest(sessionID string) *Action {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+
+	var latest *Action
+	for _, a := range s.actions {
+		if a.SessionID == sessionID && (latest == nil || a.createdAt.After(latest.createdAt)) {
+			latest = a
+		}
+	}
+	return latest
+}
+
+// sweep membuang aksi yang sudah kedaluwarsa, dipanggil dengan mu terkunci
+func (s *Store) sweep() {
+	now := time.Now()
+	for id, a := range s.actions {
+		if now.After(a.ExpiresAt) {
+			delete(s.actions, id)
+		}
+	}
+}
+
+// Diff membandingkan dua object (nested map/slice diratakan menjadi key bertitik),
+// before nil untuk data baru dan after nil untuk hapus
+func Diff(before, after map[string]any) []Change {
+	b := flatten("", before, map[string]any{})
+	a := flatten("", after, map[string]any{})
+
+	keys := map[string]bool{}
+	for k := range b {
+		keys[k] = true
+	}
+	for k := range a {
+		keys[k] = true
+	}
+
+	changes := []Change{}
+	for k := range keys {
+		bv, bok := b[k]
+		av, aok := a[k]
+		if bok && aok && fmt.Sprint(bv) == fmt.Sprint(av) {
+			continue
+		}
+		changes = append(changes, Change{Field: k, Before: bv, After: av})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func flatten(prefix string, v any, out map[string]any) map[string]any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flatten(key, item, out)
+		}
+	case []map[string]any:
+		for i, item := range val {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), item, out)
+		}
+	case []any:
+		for i, item := range val {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), item, out)
+		}
+	case nil:
+	default:
+		if prefix != "" {
+			out[prefix] = val
+		}
+	}
+	return out
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MaulanaR/zai/aggregate"
+	"github.com/MaulanaR/zai/ai"
+	"github.com/MaulanaR/zai/answer"
+	"github.com/MaulanaR/zai/period"
+	"github.com/MaulanaR/zai/prompt"
+	"github.com/MaulanaR/zai/resolve"
+	"github.com/MaulanaR/zai/session"
+	"github.com/MaulanaR/zai/tools"
+)
+
+// AgentStep jejak satu putaran agent (satu pemanggilan LLM beserta tool yang dijalankan)
+type AgentStep struct {
+	Step       int         `json:"step"`
+	Tokens     int         `json:"tokens"`
+	DurationMs int64       `json:"duration_ms"`
+	Final      bool        `json:"final,omitempty"`
+	Calls      []ToolTrace `json:"calls,omitempty"`
+}
+
+// ToolTrace jejak satu pemanggilan tool dalam AgentStep
+type ToolTrace struct {
+	Tool       string         `json:"tool"`
+	Endpoint   string         `json:"endpoint,omitempty"`
+	Params     map[string]any `json:"params,omitempty"`
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	DurationMs int64          `json:"duration_ms"`
+}
+
+// emitter mengirim event progres ke client (SSE), nil berarti tanpa streaming
+type emitter func(event string, data any)
+
+func (e emitter) send(event string, data any) {
+	if e != nil {
+		e(event, data)
+	}
+}
+
+// progress event tahapan agent yang dikirim ke client
+type progress struct {
+	Stage    string `json:"stage"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Message  string `json:"message"`
+}
+
+// runAgent menjalankan loop tool calling sampai model memberi jawaban akhir,
+// batas langkah (AgentMaxSteps) atau batas token (AgentTokenBudget) tercapai.
+// resume adalah keputusan hasil klarifikasi yang dijalankan sebelum model dipanggil
+func (bot *ChatBot) runAgent(ctx context.Context, sess *session.Session, message, bearerToken, slug string, emit emitter, resume *APIDecision) *ZahirResponse {
+	settings := bot.settings.Get(slug)
+	messages := bot.buildMessages(sess, prompt.AgentMSG(settings.Now(), settings), message)
+	trace := []AgentStep{}
+	usedTokens := 0
+
+	if resume != nil {
+		start := time.Now()
+		st := AgentStep{}
+		tt := ToolTrace{Tool: resume.Tool, Endpoint: resume.Endpoint, Params: resume.Params, Status: "OK"}
+		if res := bot.resolveEntities(ctx, sess, message, resume, bearerToken, slug); res != nil {
+			tt.Status = "clarify"
+			st.Calls = append(st.Calls, tt)
+			res.Trace = append(trace, st)
+			return res
+		}
+		args, _ := json.Marshal(resume.Params)
+		call := ai.ToolCall{ID: "resume_" + resume.Tool, Name: resume.Tool, Arguments: args}
+
+		content, err := bot.callTool(ctx, sess, resume, bearerToken, slug, emit)
+		if err != nil {
+			tt.Status, tt.Error = "error", err.Error()
+			content = "error: " + err.Error()
+		}
+		tt.DurationMs = time.Since(start).Milliseconds()
+		st.Calls = append(st.Calls, tt)
+		st.DurationMs = tt.DurationMs
+		trace = append(trace, st)
+		messages = append(messages,
+			ai.Message{Role: "assistant", ToolCalls: []ai.ToolCall{call}},
+			ai.Message{Role: "tool", ToolCallID: call.ID, Content: content},
+		)
+	}
+
+	for step := 1; ; step++ {
+		aiReq := ai.Request{
+			Model:       ModelAI,
+			Messages:    messages,
+			Temperature: 0,
+			TopP:        0.01,
+			MaxTokens:   3500,
+		}
+		// langkah terakhir tanpa tool agar model wajib menjawab dengan data yang ada
+		if step < AgentMaxSteps {
+			aiReq.Tools = tools.AITools(append(tools.Zahir, tools.AskUser, tools.ResolvePeriod))
+		}
+
+		if step == 1 {
+			emit.send("progress", progress{Stage: "deciding", Message: "Menentukan endpoint"})
+		} else {
+			emit.send("progress", progress{Stage: "interpreting", Message: "Menginterpretasi data"})
+		}
+
+		// hanya isi block text yang dikirim ke client, jika ternyata model memanggil tool
+		// atau jawabannya tidak valid maka client diminta membuang teksnya
+		var onDelta func(string)
+		streamed := false
+		if emit != nil {
+			onDelta = answer.NewTextStream(func(text string) {
+				streamed = true
+				emit.send("token", map[string]string{"text": text})
+			}).Write
+		}
+
+		start := time.Now()
+		aiResp, err := bot.completeStream(ctx, aiReq, onDelta)
+		if err != nil {
+			return &ZahirResponse{
+				Status:  "error",
+				Message: fmt.Sprintf("Gagal memanggil AI: %v", err),
+				Trace:   trace,
+			}
+		}
+		usedTokens += aiResp.Usage.TotalTokens
+		st := AgentStep{Step: step, Tokens: aiResp.Usage.TotalTokens}
+
+		if len(aiResp.ToolCalls) == 0 {
+			st.DurationMs = time.Since(start).Milliseconds()
+			blocks, err := answer.Parse(aiResp.Content)
+			if err != nil {
+				decisionParseFailuresTotal.WithLabelValues("answer").Inc()
+				trace = append(trace, st)
+				if streamed {
+					emit.send("discard", struct{}{})
+				}
+				if step >= AgentMaxSteps {
+					return &ZahirResponse{
+						Status:  "error",
+						Message: fmt.Sprintf("Gagal menyusun jawaban: %v", err),
+						Trace:   trace,
+					}
+				}
+				// minta model memperbaiki format jawaban
+				messages = append(messages,
+					ai.Message{Role: "assistant", Content: aiResp.Content},
+					ai.Message{Role: "user", Content: fmt.Sprintf(`Jawaban tidak valid: %v. Kirim ulang jawaban hanya sebagai JSON {"blocks": [...]} sesuai response_format.`, err)},
+				)
+				continue
+			}
+
+			st.Final = true
+			trace = append(trace, st)
+
+			// add to history
+			sess.AddTurn("user", message)
+			sess.AddTurn("assistant", aiResp.Content)
+
+			return &ZahirResponse{
+				Status:  "OK",
+				Message: answer.PlainText(blocks),
+				Blocks:  blocks,
+				Trace:   trace,
+			}
+		}
+
+		if AgentTokenBudget > 0 && usedTokens > AgentTokenBudget {
+			st.DurationMs = time.Since(start).Milliseconds()
+			trace = append(trace, st)
+			return &ZahirResponse{
+				Status:  "error",
+				Message: fmt.Sprintf("Batas token agent terlampaui (%d dari %d)", usedTokens, AgentTokenBudget),
+				Trace:   trace,
+			}
+		}
+
+		if streamed {
+			emit.send("discard", struct{}{})
+		}
+
+		messages = append(messages, ai.Message{Role: "assistant", Content: aiResp.Content, ToolCalls: aiResp.ToolCalls})
+		for _, call := range aiResp.ToolCalls {
+			callStart := time.Now()
+			tt := ToolTrace{Tool: call.Name}
+
+			decision, err := toolCallDecision(call)
+			if err != nil {
+				decisionParseFailuresTotal.WithLabelValues("tool_call").Inc()
+				// kembalikan error ke model agar bisa memperbaiki argumennya
+				tt.Status = "error"
+				tt.Error = err.Error()
+				st.Calls = append(st.Calls, tt)
+				messages = append(messages, ai.Message{Role: "tool", ToolCallID: call.ID, Content: "error: " + err.Error()})
+				continue
+			}
+			tt.Endpoint = decision.Endpoint
+			tt.Params = decision.Params
+
+			if decision.Clarify != nil {
+				tt.Status = "clarify"
+				st.Calls = append(st.Calls, tt)
+				st.DurationMs = time.Since(start).Milliseconds()
+				res := bot.clarify(sess, message, decision.Clarify, nil)
+				res.Trace = append(trace, st)
+				return res
+			}
+
+			if decision.Input {
+				tt.Status = "input"
+				st.Calls = append(st.Calls, tt)
+				st.DurationMs = time.Since(start).Milliseconds()
+				res := bot.handleInput(ctx, sess, decision, bearerToken, slug)
+				res.Trace = append(trace, st)
+				return res
+			}
+
+			// nama yang diketik user diganti nama di Zahir, jika ada beberapa kandidat user ditanya dulu
+			if res := bot.resolveEntities(ctx, sess, message, decision, bearerToken, slug); res != nil {
+				tt.Status = "clarify"
+				st.Calls = append(st.Calls, tt)
+				st.DurationMs = time.Since(start).Milliseconds()
+				res.Trace = append(trace, st)
+				return res
+			}
+
+			var content string
+			if decision.Tool == tools.ResolvePeriod.Name {
+				content, err = resolvePeriod(decision.Params, settings.Now())
+			} else {
+				content, err = bot.callTool(ctx, sess, decision, bearerToken, slug, emit)
+			}
+			tt.DurationMs = time.Since(callStart).Milliseconds()
+			if err != nil {
+				slog.WarnContext(ctx, "tool call", "stage", "fetch", "tool", decision.Tool, "endpoint", decision.Endpoint, "duration_ms", tt.DurationMs, "err", err)
+				tt.Status = "error"
+				tt.Error = err.Error()
+				st.Calls = append(st.Calls, tt)
+				messages = append(messages, ai.Message{Role: "tool", ToolCallID: call.ID, Content: "error: " + err.Error()})
+				continue
+			}
+
+			slog.InfoContext(ctx, "tool call", "stage", "fetch", "tool", decision.Tool, "endpoint", decision.Endpoint, "duration_ms", tt.DurationMs)
+			tt.Status = "OK"
+			st.Calls = append(st.Calls, tt)
+			messages = append(messages, ai.Message{Role: "tool", ToolCallID: call.ID, Content: content})
+		}
+		st.DurationMs = time.Since(start).Milliseconds()
+		trace = append(trace, st)
+	}
+}
+
+// callTool mengambil data Zahir untuk satu keputusan, hasilnya disimpan di session dan dikirim ke model sebagai JSON
+func (bot *ChatBot) callTool(ctx context.Context, sess *session.Session, decision *APIDecision, bearerToken, slug string, emit emitter) (string, error) {
+	emit.send("progress", progress{Stage: "fetching", Endpoint: decision.Endpoint, Message: "Mengambil data " + decision.Endpoint})
+	apiResp, err := bot.fetchData(ctx, decision, bearerToken, slug)
+	if err != nil {
+		return "", err
+	}
+	apiData, err := json.Marshal(apiResp)
+	if err != nil {
+		return "", fmt.Errorf("gagal membaca data: %v", err)
+	}
+	sess.SetData(decision.Endpoint, string(apiData))
+	return string(apiData), nil
+}
+
+// resolvePeriod menghitung rentang tanggal untuk tool resolve_period relatif terhadap now
+func resolvePeriod(params map[string]any, now time.Time) (string, error) {
+	expr, _ := params["expression"].(string)
+	r, err := period.Parse(expr, now)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(map[string]string{
+		"expression": expr,
+		"from":       r.From.Format(period.Layout),
+		"to":         r.To.Format(period.Layout),
+		"label":      r.Label(),
+	})
+	return string(b), err
+}
+
+// toolCallDecision memvalidasi tool call dari model dan mengubahnya menjadi APIDecision
+func toolCallDecision(call ai.ToolCall) (*APIDecision, error) {
+	if call.Name == tools.AskUser.Name {
+		params, err := tools.AskUser.ParseArgs(call.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		c := &Clarify{}
+		c.Question, _ = params["question"].(string)
+		c.Choices, _ = params["choices"].([]string)
+		c.Slot, _ = params["slot"].(string)
+		return &APIDecision{Tool: call.Name, Params: params, Clarify: c}, nil
+	}
+
+	if call.Name == tools.ResolvePeriod.Name {
+		params, err := tools.ResolvePeriod.ParseArgs(call.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return &APIDecision{Tool: call.Name, Params: params}, nil
+	}
+
+	def, ok := tools.Find(tools.Zahir, call.Name)
+	if !ok {
+		return nil, fmt.Errorf("unknown tool %q", call.Name)
+	}
+	params, err := def.ParseArgs(call.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &APIDecision{
+		Input:    def.Method != http.MethodGet,
+		Tool:     def.Name,
+		Method:   def.Method,
+		Endpoint: def.Endpoint,
+		Type:     def.Type,
+		Params:   params,
+	}
+	if def.Aggregate {
+		api, local := def.Split(params)
+		q := aggregateQuery(local)
+		if q.Func != aggregate.Count && q.Field == "" {
+			return nil, fmt.Errorf("tool %s: invalid arguments: field is required for metric %s", def.Name, q.Func)
+		}
+		if usesLineItems(q) {
+			api["includes[line_items]"] = "true"
+		}
+		decision.Params = api
+		decision.Aggregate = &q
+	}
+	for _, p := range def.Params {
+		key := p.Key
+		if key == "" {
+			key = p.Name
+		}
+		if _, ok := decision.Params[key]; ok && p.Entity != "" {
+			if decision.Entities == nil {
+				decision.Entities = map[string]resolve.Kind{}
+			}
+			decision.Entities[key] = p.Entity
+		}
+	}
+	if def.Method == http.MethodPatch || def.Method == http.MethodDelete {
+		api, local := def.Split(params)
+		decision.Params = api
+		decision.Target, _ = local["target"].(string)
+	}
+	return decision, nil
+}
+
+// resolveEntities mengganti nama kontak/produk pada params dengan nama di Zahir. Jika beberapa kandidat
+// skornya berdekatan, dikembalikan pertanyaan klarifikasi; nama yang tidak ditemukan dibiarkan apa adanya
+func (bot *ChatBot) resolveEntities(ctx context.Context, sess *session.Session, message string, decision *APIDecision, bearerToken, slug string) *ZahirResponse {
+	client := bot.zahirClient(bearerToken, slug)
+	for key, kind := range decision.Entities {
+		query, _ := decision.Params[key].(string)
+		if query == "" {
+			continue
+		}
+		cands, err := bot.entities.Resolve(ctx, client, kind, query)
+		if err != nil {
+			slog.WarnContext(ctx, "resolve failed", "kind", kind, "query", query, "err", err)
+			continue
+		}
+		if best, ok := resolve.Pick(cands); ok {
+			decision.Params[key] = best.Name
+			continue
+		}
+		if len(cands) == 0 {
+			continue
+		}
+
+		e := &resolve.Error{Kind: kind, Query: query, Candidates: cands}
+		res := bot.clarify(sess, message, &Clarify{
+			Question: fmt.Sprintf("Ada beberapa %s yang cocok dengan %q: %s. Mana yang Anda maksud?", kind, query, strings.Join(e.Names(), ", ")),
+			Choices:  e.Names(),
+			Slot:     key,
+		}, decision)
+		res.Data = cands
+		return res
+	}
+	return nil
+}
+
+// aggregateQuery membuat aggregate.Query dari params lokal tool aggregate_*
+func aggregateQuery(local map[string]any) aggregate.Query {
+	q := aggregate.Query{}
+	q.Func, _ = local["metric"].(string)
+	q.Field, _ = local["field"].(string)
+	q.GroupBy, _ = local["group_by"].(string)
+	q.Period, _ = local["period"].(string)
+	if top, ok := local["top"].(float64); ok {
+		q.Top = int(top)
+	}
+	q.Order, _ = local["order"].(string)
+	// period tanpa group_by berarti dikelompokkan per tanggal
+	if q.Period != "" && q.GroupBy == "" {
+		q.GroupBy = "date"
+	}
+	return q
+}
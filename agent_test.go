@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MaulanaR/zai/ai"
+	"github.com/MaulanaR/zai/auth"
+	"github.com/MaulanaR/zai/tenant"
+)
+
+// fakeLLM LLMClient yang mengembalikan response berurutan dan mencatat setiap request
+type fakeLLM struct {
+	responses []*ai.Response
+	requests  []ai.Request
+}
+
+func (f *fakeLLM) Complete(_ context.Context, req ai.Request) (*ai.Response, error) {
+	f.requests = append(f.requests, req)
+	if len(f.requests) > len(f.responses) {
+		return nil, errors.New("unexpected LLM call")
+	}
+	return f.responses[len(f.requests)-1], nil
+}
+
+func TestAgentToolCallThenAnswer(t *testing.T) {
+	var zahirQuery string
+	zahirSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/contacts" {
+			http.NotFound(w, r)
+			return
+		}
+		zahirQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results": [{"id": "c1", "name": "Budi Santoso", "is_customer": true}], "count": 1, "page": 1, "per_page": 10, "total_pages": 1}`))
+	}))
+	defer zahirSrv.Close()
+
+	defer func(url string) { BaseAPIURL = url }(BaseAPIURL)
+	BaseAPIURL = zahirSrv.URL
+
+	llm := &fakeLLM{responses: []*ai.Response{
+		{
+			ToolCalls: []ai.ToolCall{{ID: "call_1", Name: "list_contacts", Arguments: json.RawMessage(`{"is_customer": true}`)}},
+			Usage:     ai.Usage{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110},
+		},
+		{
+			Content: `{"blocks": [{"type": "text", "text": "Customer: Budi Santoso"}]}`,
+			Usage:   ai.Usage{PromptTokens: 150, CompletionTokens: 20, TotalTokens: 170},
+		},
+	}}
+	settings, err := tenant.NewStore("", defaultSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := NewChatBot(llm, llm, settings, nil, nil)
+	ctx := auth.NewContext(context.Background(), &auth.Principal{BearerToken: "tok", Slug: "s", Role: auth.Editor})
+
+	res := bot.ProcessMessage(ctx, WebhookRequest{Message: "siapa saja customer kita?"})
+
+	if res.Status != "OK" || res.Message != "Customer: Budi Santoso" {
+		t.Fatalf("response = %s %q, want OK %q", res.Status, res.Message, "Customer: Budi Santoso")
+	}
+	if len(llm.requests) != 2 {
+		t.Fatalf("LLM dipanggil %d kali, want 2", len(llm.requests))
+	}
+	if len(llm.requests[0].Tools) == 0 {
+		t.Error("request pertama tidak membawa deklarasi tool")
+	}
+	if !strings.Contains(zahirQuery, "is_customer") {
+		t.Errorf("query Zahir %q tidak memuat filter is_customer", zahirQuery)
+	}
+
+	// hasil tool dikirim balik ke model sebagai pesan tool dengan ID pemanggilan yang sama
+	msgs := llm.requests[1].Messages
+	last := msgs[len(msgs)-1]
+	if last.Role != "tool" || last.ToolCallID != "call_1" || !strings.Contains(last.Content, "Budi Santoso") {
+		t.Errorf("pesan terakhir ke model = %+v, want hasil tool call_1 berisi data kontak", last)
+	}
+	if prev := msgs[len(msgs)-2]; prev.Role != "assistant" || len(prev.ToolCalls) != 1 {
+		t.Errorf("pesan sebelum hasil tool = %+v, want assistant dengan tool call", prev)
+	}
+
+	if len(res.Trace) != 2 || !res.Trace[1].Final || len(res.Trace[0].Calls) != 1 || res.Trace[0].Calls[0].Status != "OK" {
+		t.Errorf("trace = %+v, want 2 langkah: tool call OK lalu jawaban akhir", res.Trace)
+	}
+}
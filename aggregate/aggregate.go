@@ -0,0 +1,255 @@
+// Package aggregate menghitung group-by, sum, count, avg, min/max dan top-N
+// atas data Zahir secara lokal, supaya angka tidak dihitung oleh LLM.
+package aggregate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Fungsi agregasi yang didukung
+const (
+	Sum   = "sum"
+	Count = "count"
+	Avg   = "avg"
+	Min   = "min"
+	Max   = "max"
+)
+
+// Periode pengelompokan untuk field tanggal
+const (
+	Day   = "day"
+	Month = "month"
+	Year  = "year"
+)
+
+// EmptyKey nama group untuk record yang field group-nya kosong
+const EmptyKey = "(kosong)"
+
+// Record satu baris data dengan key sesuai json tag model, misal "customer.name"
+type Record map[string]any
+
+// Query definisi agregasi yang dijalankan oleh Run
+type Query struct {
+	GroupBy string // field pengelompokan, kosong berarti semua record satu group
+	Period  string // day, month atau year jika GroupBy berisi tanggal
+	Func    string // sum, count, avg, min, max
+	Field   string // field angka, tidak wajib untuk count
+	Top     int    // batasi jumlah group setelah diurutkan, 0 berarti semua
+	Order   string // asc atau desc; kosong berarti nilai terbesar dulu, atau kronologis untuk periode
+}
+
+// Row hasil agregasi satu group
+type Row struct {
+	Key   string  `json:"key"`
+	Value float64 `json:"value"`
+	Count int     `json:"count"`
+}
+
+// Result hasil agregasi, Total dan Count dihitung dari semua record walaupun Rows dibatasi Top
+type Result struct {
+	GroupBy string  `json:"group_by,omitempty"`
+	Period  string  `json:"period,omitempty"`
+	Func    string  `json:"func"`
+	Field   string  `json:"field,omitempty"`
+	Groups  int     `json:"groups"`
+	Count   int     `json:"count"`
+	Total   float64 `json:"total"`
+	Rows    []Row   `json:"rows"`
+}
+
+// FromModels mengubah slice model (misal []model.SalesInvoiceDetail) menjadi []Record lewat JSON,
+// sehingga key record sama dengan json tag model
+func FromModels(v any) ([]Record, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	records := []Record{}
+	if err := json.Unmarshal(b, &records); err != nil {
+		return nil, fmt.Errorf("aggregate: data is not a list of objects: %w", err)
+	}
+	return records, nil
+}
+
+// Unnest memecah field list (misal "line_items") menjadi satu record per item.
+// Field item diberi prefix "<field>." dan field induk ikut disalin, record tanpa item dibuang
+func Unnest(records []Record, field string) []Record {
+	res := []Record{}
+	for _, r := range records {
+		items, _ := r[field].([]any)
+		for _, it := range items {
+			item, ok := it.(map[string]any)
+			if !ok {
+				continue
+			}
+			row := make(Record, len(r)+len(item))
+			for k, v := range r {
+				if k != field {
+					row[k] = v
+				}
+			}
+			for k, v := range item {
+				row[field+"."+k] = v
+			}
+			res = append(res, row)
+		}
+	}
+	return res
+}
+
+// Run menjalankan q atas records
+func Run(records []Record, q Query) (*Result, error) {
+	switch q.Func {
+	case Sum, Avg, Min, Max:
+		if q.Field == "" {
+			return nil, fmt.Errorf("aggregate: field is required for %s", q.Func)
+		}
+	case Count:
+	default:
+		return nil, fmt.Errorf("aggregate: unknown func %q", q.Func)
+	}
+	switch q.Period {
+	case "", Day, Month, Year:
+	default:
+		return nil, fmt.Errorf("aggregate: unknown period %q", q.Period)
+	}
+
+	type group struct {
+		key        string
+		sum        float64
+		min, max   float64
+		count, num int
+	}
+	groups := map[string]*group{}
+	order := []string{}
+
+	for _, r := range records {
+		key := ""
+		if q.GroupBy != "" {
+			key = groupKey(r[q.GroupBy], q.Period)
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{key: key}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.count++
+
+		if q.Field == "" {
+			continue
+		}
+		v, ok := number(r[q.Field])
+		if !ok {
+			continue
+		}
+		if g.num == 0 || v < g.min {
+			g.min = v
+		}
+		if g.num == 0 || v > g.max {
+			g.max = v
+		}
+		g.sum += v
+		g.num++
+	}
+
+	res := &Result{GroupBy: q.GroupBy, Period: q.Period, Func: q.Func, Field: q.Field, Count: len(records), Rows: []Row{}}
+	for _, key := range order {
+		g := groups[key]
+		row := Row{Key: g.key, Count: g.count}
+		switch q.Func {
+		case Sum:
+			row.Value = g.sum
+		case Count:
+			row.Value = float64(g.count)
+		case Avg:
+			if g.num > 0 {
+				row.Value = g.sum / float64(g.num)
+			}
+		case Min:
+			row.Value = g.min
+		case Max:
+			row.Value = g.max
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	res.Groups = len(res.Rows)
+
+	// total untuk seluruh record, avg/min/max dihitung ulang tanpa group
+	if q.GroupBy == "" {
+		if len(res.Rows) > 0 {
+			res.Total = res.Rows[0].Value
+		}
+	} else {
+		all, err := Run(records, Query{Func: q.Func, Field: q.Field})
+		if err != nil {
+			return nil, err
+		}
+		res.Total = all.Total
+	}
+
+	// periode diurutkan kronologis (terlama dulu, desc untuk terbaru dulu) kecuali diminta top-N
+	if q.Period != "" && q.Top == 0 {
+		sort.SliceStable(res.Rows, func(i, j int) bool {
+			if q.Order == "desc" {
+				return res.Rows[i].Key > res.Rows[j].Key
+			}
+			return res.Rows[i].Key < res.Rows[j].Key
+		})
+	} else {
+		sort.SliceStable(res.Rows, func(i, j int) bool {
+			a, b := res.Rows[i], res.Rows[j]
+			if a.Value == b.Value {
+				return a.Key < b.Key
+			}
+			if q.Order == "asc" {
+				return a.Value < b.Value
+			}
+			return a.Value > b.Value
+		})
+	}
+	if q.Top > 0 && len(res.Rows) > q.Top {
+		res.Rows = res.Rows[:q.Top]
+	}
+	return res, nil
+}
+
+// groupKey mengubah nilai field menjadi nama group, tanggal dipotong sesuai periode
+func groupKey(v any, period string) string {
+	if v == nil {
+		return EmptyKey
+	}
+	key := strings.TrimSpace(fmt.Sprint(v))
+	if key == "" {
+		return EmptyKey
+	}
+	n := 0
+	switch period {
+	case Day:
+		n = len("2006-01-02")
+	case Month:
+		n = len("2006-01")
+	case Year:
+		n = len("2006")
+	}
+	if n > 0 && len(key) >= n {
+		key = key[:n]
+	}
+	return key
+}
+
+// number membaca angka dari nilai JSON, string angka juga diterima
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,42 @@
+package aggregate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRunOrder(t *testing.T) {
+	records := []Record{
+		{"date": "2026-01-15", "customer.name": "Andi", "total_amount": 100.0},
+		{"date": "2026-03-02", "customer.name": "Budi", "total_amount": 300.0},
+		{"date": "2026-02-20", "customer.name": "Citra", "total_amount": 200.0},
+		{"date": "2026-03-28", "customer.name": "Andi", "total_amount": 50.0},
+	}
+	tests := []struct {
+		name string
+		q    Query
+		want []string
+	}{
+		{"periode default kronologis", Query{GroupBy: "date", Period: Month, Func: "sum", Field: "total_amount"}, []string{"2026-01", "2026-02", "2026-03"}},
+		{"periode desc terbaru dulu", Query{GroupBy: "date", Period: Month, Func: "sum", Field: "total_amount", Order: "desc"}, []string{"2026-03", "2026-02", "2026-01"}},
+		{"periode top-N menurut nilai", Query{GroupBy: "date", Period: Month, Func: "sum", Field: "total_amount", Top: 2}, []string{"2026-03", "2026-02"}},
+		{"group default nilai terbesar", Query{GroupBy: "customer.name", Func: "sum", Field: "total_amount"}, []string{"Budi", "Citra", "Andi"}},
+		{"group asc nilai terkecil", Query{GroupBy: "customer.name", Func: "sum", Field: "total_amount", Order: "asc"}, []string{"Andi", "Citra", "Budi"}},
+	}
+	for _, tt := range tests {
+		res, err := Run(records, tt.q)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		keys := []string{}
+		for _, r := range res.Rows {
+			keys = append(keys, r.Key)
+		}
+		if !reflect.DeepEqual(keys, tt.want) {
+			t.Errorf("%s: rows = %v, want %v", tt.name, keys, tt.want)
+		}
+		if res.Total != 650 {
+			t.Errorf("%s: total = %v, want 650", tt.name, res.Total)
+		}
+	}
+}
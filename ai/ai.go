@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Provider yang didukung, dipilih lewat env LLM_PROVIDER / VISION_PROVIDER
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// LLMClient abstraksi untuk semua pemanggilan model bahasa
+type LLMClient interface {
+	Complete(ctx context.Context, req Request) (*Response, error)
+}
+
+// Message satu pesan dalam percakapan
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+
+	// Images berisi URL atau data URL (base64) gambar untuk model vision
+	Images []string `json:"images,omitempty"`
+
+	// ToolCalls diisi pada pesan assistant yang memanggil tool
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID diisi pada pesan role "tool" berisi hasil tool
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// ResponseFormat memaksa format output model, misal {"type": "json_object"}
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// Tool deklarasi function yang boleh dipanggil model
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters"` // JSON Schema
+}
+
+// ToolCall pemanggilan tool yang dikembalikan model
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// Request parameter pemanggilan model
+type Request struct {
+	Model          string
+	Messages       []Message
+	Temperature    float64
+	TopP           float64
+	MaxTokens      int
+	ResponseFormat *ResponseFormat
+	Tools          []Tool
+}
+
+// Usage jumlah token yang dipakai satu pemanggilan
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Response hasil pemanggilan model
+type Response struct {
+	Model        string
+	Content      string
+	ToolCalls    []ToolCall
+	FinishReason string
+	Usage        Usage
+}
+
+// New membuat LLMClient sesuai provider, provider kosong berarti openai
+func New(provider, url, apiKey string, client *http.Client) (LLMClient, error) {
+	switch strings.ToLower(provider) {
+	case "", ProviderOpenAI:
+		return NewOpenAI(url, apiKey, client), nil
+	case ProviderAnthropic:
+		return NewAnthropic(url, apiKey, client), nil
+	case ProviderOllama:
+		return NewOllama(url, client), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", provider)
+	}
+}
+
+// splitDataURL memecah "data:image/png;base64,xxxx" menjadi media type dan data base64
+func splitDataURL(s string) (mediaType, data string, ok bool) {
+	if !strings.HasPrefix(s, "data:") {
+		return "", "", false
+	}
+	header, data, found := strings.Cut(strings.TrimPrefix(s, "data:"), ",")
+	if !found || !strings.HasSuffix(header, ";base64") {
+		return "", "", false
+	}
+	return strings.TrimSuffix(header, ";base64"), data, true
+}
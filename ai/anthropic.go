@@ -0,0 +1,272 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	AnthropicDefaultURL = "https://api.anthropic.com/v1/messages"
+	AnthropicVersion    = "2023-06-01"
+)
+
+// Anthropic client untuk Anthropic Messages API
+type Anthropic struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+func NewAnthropic(url, apiKey string, client *http.Client) *Anthropic {
+	if url == "" {
+		url = AnthropicDefaultURL
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Anthropic{URL: url, APIKey: apiKey, Client: client}
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+type anthropicBlock struct {
+	Type string `json:"type"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// image
+	Source *anthropicImageSource `json:"source,omitempty"`
+
+	// tool_use
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// tool_result
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicResponse struct {
+	Model      string           `json:"model"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (c *Anthropic) Complete(ctx context.Context, req Request) (*Response, error) {
+	resp, err := postJSON(ctx, c.Client, "anthropic", c.URL, c.headers(), c.buildRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var aResp anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&aResp); err != nil {
+		return nil, err
+	}
+
+	res := &Response{
+		Model:        aResp.Model,
+		FinishReason: aResp.StopReason,
+		Usage: Usage{
+			PromptTokens:     aResp.Usage.InputTokens,
+			CompletionTokens: aResp.Usage.OutputTokens,
+			TotalTokens:      aResp.Usage.InputTokens + aResp.Usage.OutputTokens,
+		},
+	}
+	for _, b := range aResp.Content {
+		switch b.Type {
+		case "text":
+			res.Content += b.Text
+		case "tool_use":
+			res.ToolCalls = append(res.ToolCalls, ToolCall{ID: b.ID, Name: b.Name, Arguments: b.Input})
+		}
+	}
+	return res, nil
+}
+
+type anthropicStreamEvent struct {
+	Type         string             `json:"type"`
+	Index        int                `json:"index"`
+	Message      *anthropicResponse `json:"message"`
+	ContentBlock *anthropicBlock    `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *Anthropic) Stream(ctx context.Context, req Request, onDelta func(text string)) (*Response, error) {
+	aReq := c.buildRequest(req)
+	aReq.Stream = true
+
+	resp, err := postJSON(ctx, c.Client, "anthropic", c.URL, c.headers(), aReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	res := &Response{}
+	blocks := map[int]*ToolCall{}
+	order := []int{}
+	err = readSSE(resp.Body, func(_ string, data []byte) error {
+		var ev anthropicStreamEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return err
+		}
+		switch ev.Type {
+		case "message_start":
+			if ev.Message != nil {
+				res.Model = ev.Message.Model
+				res.Usage.PromptTokens = ev.Message.Usage.InputTokens
+			}
+		case "content_block_start":
+			if ev.ContentBlock != nil && ev.ContentBlock.Type == "tool_use" {
+				blocks[ev.Index] = &ToolCall{ID: ev.ContentBlock.ID, Name: ev.ContentBlock.Name}
+				order = append(order, ev.Index)
+			}
+		case "content_block_delta":
+			switch ev.Delta.Type {
+			case "text_delta":
+				res.Content += ev.Delta.Text
+				onDelta(ev.Delta.Text)
+			case "input_json_delta":
+				if tc, ok := blocks[ev.Index]; ok {
+					tc.Arguments = append(tc.Arguments, ev.Delta.PartialJSON...)
+				}
+			}
+		case "message_delta":
+			if ev.Delta.StopReason != "" {
+				res.FinishReason = ev.Delta.StopReason
+			}
+			if ev.Usage != nil {
+				res.Usage.CompletionTokens = ev.Usage.OutputTokens
+			}
+		case "error":
+			if ev.Error != nil {
+				return fmt.Errorf("anthropic: %s: %s", ev.Error.Type, ev.Error.Message)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.Usage.TotalTokens = res.Usage.PromptTokens + res.Usage.CompletionTokens
+	for _, i := range order {
+		tc := *blocks[i]
+		if len(tc.Arguments) == 0 {
+			tc.Arguments = json.RawMessage("{}")
+		}
+		res.ToolCalls = append(res.ToolCalls, tc)
+	}
+	return res, nil
+}
+
+func (c *Anthropic) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         c.APIKey,
+		"anthropic-version": AnthropicVersion,
+	}
+}
+
+func (c *Anthropic) buildRequest(req Request) anthropicRequest {
+	aReq := anthropicRequest{
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		// top_p tidak dikirim, model terbaru menolak temperature dan top_p sekaligus
+	}
+	if aReq.MaxTokens == 0 {
+		aReq.MaxTokens = 4096
+	}
+	for _, t := range req.Tools {
+		aReq.Tools = append(aReq.Tools, anthropicTool{Name: t.Name, Description: t.Description, InputSchema: t.Parameters})
+	}
+
+	// semua pesan system digabung ke field system
+	system := []string{}
+	if req.ResponseFormat != nil && req.ResponseFormat.Type == "json_object" {
+		system = append(system, "Respond only with a valid JSON object.")
+	}
+	for _, m := range req.Messages {
+		switch m.Role {
+		case "system":
+			system = append(system, m.Content)
+		case "tool":
+			aReq.Messages = append(aReq.Messages, anthropicMessage{
+				Role:    "user",
+				Content: []anthropicBlock{{Type: "tool_result", ToolUseID: m.ToolCallID, Content: m.Content}},
+			})
+		default:
+			msg := anthropicMessage{Role: m.Role}
+			for _, img := range m.Images {
+				msg.Content = append(msg.Content, anthropicBlock{Type: "image", Source: anthropicImage(img)})
+			}
+			if m.Content != "" {
+				msg.Content = append(msg.Content, anthropicBlock{Type: "text", Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				input := tc.Arguments
+				if len(input) == 0 {
+					input = json.RawMessage("{}")
+				}
+				msg.Content = append(msg.Content, anthropicBlock{Type: "tool_use", ID: tc.ID, Name: tc.Name, Input: input})
+			}
+			aReq.Messages = append(aReq.Messages, msg)
+		}
+	}
+	aReq.System = strings.Join(system, "\n\n")
+	return aReq
+}
+
+func anthropicImage(img string) *anthropicImageSource {
+	if mediaType, data, ok := splitDataURL(img); ok {
+		return &anthropicImageSource{Type: "base64", MediaType: mediaType, Data: data}
+	}
+	return &anthropicImageSource{Type: "url", URL: img}
+}
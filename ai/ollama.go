@@ -0,0 +1,172 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const OllamaDefaultURL = "http://localhost:11434/api/chat"
+
+// Ollama client untuk endpoint /api/chat milik Ollama (model lokal)
+type Ollama struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewOllama(url string, client *http.Client) *Ollama {
+	if url == "" {
+		url = OllamaDefaultURL
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Ollama{URL: url, Client: client}
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   string          `json:"format,omitempty"`
+	Tools    []openAITool    `json:"tools,omitempty"`
+	Options  map[string]any  `json:"options,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+}
+
+func (c *Ollama) Complete(ctx context.Context, req Request) (*Response, error) {
+	resp, err := postJSON(ctx, c.Client, "ollama", c.URL, nil, c.buildRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var oResp ollamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&oResp); err != nil {
+		return nil, err
+	}
+
+	res := &Response{}
+	c.merge(res, oResp, nil)
+	return res, nil
+}
+
+// Stream membaca response NDJSON, satu objek JSON per baris sampai done
+func (c *Ollama) Stream(ctx context.Context, req Request, onDelta func(text string)) (*Response, error) {
+	oReq := c.buildRequest(req)
+	oReq.Stream = true
+
+	resp, err := postJSON(ctx, c.Client, "ollama", c.URL, nil, oReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	res := &Response{}
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var chunk ollamaResponse
+		if err := dec.Decode(&chunk); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		c.merge(res, chunk, onDelta)
+		if chunk.Done {
+			break
+		}
+	}
+	return res, nil
+}
+
+// merge menambahkan satu response/chunk Ollama ke res
+func (c *Ollama) merge(res *Response, chunk ollamaResponse, onDelta func(text string)) {
+	if chunk.Model != "" {
+		res.Model = chunk.Model
+	}
+	if chunk.Message.Content != "" {
+		res.Content += chunk.Message.Content
+		if onDelta != nil {
+			onDelta(chunk.Message.Content)
+		}
+	}
+	// Ollama tidak memberi id pada tool call, id dibuat dari urutan
+	for _, tc := range chunk.Message.ToolCalls {
+		res.ToolCalls = append(res.ToolCalls, ToolCall{
+			ID:        fmt.Sprintf("call_%d", len(res.ToolCalls)),
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
+	}
+	if chunk.DoneReason != "" {
+		res.FinishReason = chunk.DoneReason
+	}
+	if chunk.PromptEvalCount > 0 || chunk.EvalCount > 0 {
+		res.Usage = Usage{
+			PromptTokens:     chunk.PromptEvalCount,
+			CompletionTokens: chunk.EvalCount,
+			TotalTokens:      chunk.PromptEvalCount + chunk.EvalCount,
+		}
+	}
+}
+
+func (c *Ollama) buildRequest(req Request) ollamaRequest {
+	oReq := ollamaRequest{
+		Model:   req.Model,
+		Options: map[string]any{"temperature": req.Temperature},
+	}
+	if req.TopP > 0 {
+		oReq.Options["top_p"] = req.TopP
+	}
+	if req.MaxTokens > 0 {
+		oReq.Options["num_predict"] = req.MaxTokens
+	}
+	if req.ResponseFormat != nil && req.ResponseFormat.Type == "json_object" {
+		oReq.Format = "json"
+	}
+	for _, t := range req.Tools {
+		oReq.Tools = append(oReq.Tools, openAITool{Type: "function", Function: t})
+	}
+	for _, m := range req.Messages {
+		oMsg := ollamaMessage{Role: m.Role, Content: m.Content}
+		for _, img := range m.Images {
+			// Ollama hanya menerima base64 tanpa prefix data URL
+			if _, data, ok := splitDataURL(img); ok {
+				img = data
+			}
+			oMsg.Images = append(oMsg.Images, img)
+		}
+		for _, tc := range m.ToolCalls {
+			otc := ollamaToolCall{}
+			otc.Function.Name = tc.Name
+			otc.Function.Arguments = tc.Arguments
+			oMsg.ToolCalls = append(oMsg.ToolCalls, otc)
+		}
+		oReq.Messages = append(oReq.Messages, oMsg)
+	}
+	return oReq
+}
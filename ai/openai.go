@@ -0,0 +1,236 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// OpenAI client untuk endpoint chat completions yang kompatibel dengan OpenAI (Groq, dll)
+type OpenAI struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+func NewOpenAI(url, apiKey string, client *http.Client) *OpenAI {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &OpenAI{URL: url, APIKey: apiKey, Client: client}
+}
+
+type openAIRequest struct {
+	Model          string          `json:"model"`
+	Messages       []openAIMessage `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	TopP           float64         `json:"top_p,omitempty"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	Tools          []openAITool    `json:"tools,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
+	StreamOptions  map[string]bool `json:"stream_options,omitempty"`
+}
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    any              `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAIContentPart struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	ImageURL map[string]string `json:"image_url,omitempty"`
+}
+
+type openAITool struct {
+	Type     string `json:"type"`
+	Function Tool   `json:"function"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content   string           `json:"content"`
+			ToolCalls []openAIToolCall `json:"tool_calls"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage Usage `json:"usage"`
+}
+
+func (c *OpenAI) Complete(ctx context.Context, req Request) (*Response, error) {
+	resp, err := postJSON(ctx, c.Client, "openai", c.URL, c.headers(), c.buildRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var oResp openAIResponse
+	if err := json.Unmarshal(body, &oResp); err != nil {
+		return nil, err
+	}
+	if len(oResp.Choices) == 0 {
+		return nil, fmt.Errorf("invalid response format from AI, detail %s", body)
+	}
+
+	choice := oResp.Choices[0]
+	res := &Response{
+		Model:        oResp.Model,
+		Content:      choice.Message.Content,
+		FinishReason: choice.FinishReason,
+		Usage:        oResp.Usage,
+	}
+	for _, tc := range choice.Message.ToolCalls {
+		res.ToolCalls = append(res.ToolCalls, ToolCall{
+			ID:        tc.ID,
+			Name:      tc.Function.Name,
+			Arguments: json.RawMessage(tc.Function.Arguments),
+		})
+	}
+	return res, nil
+}
+
+type openAIStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index int `json:"index"`
+				openAIToolCall
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+	// Groq mengirim usage di x_groq pada chunk terakhir
+	XGroq *struct {
+		Usage *Usage `json:"usage"`
+	} `json:"x_groq"`
+}
+
+func (c *OpenAI) Stream(ctx context.Context, req Request, onDelta func(text string)) (*Response, error) {
+	oReq := c.buildRequest(req)
+	oReq.Stream = true
+	oReq.StreamOptions = map[string]bool{"include_usage": true}
+
+	resp, err := postJSON(ctx, c.Client, "openai", c.URL, c.headers(), oReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	res := &Response{}
+	calls := map[int]*openAIToolCall{}
+	order := []int{}
+	err = readSSE(resp.Body, func(_ string, data []byte) error {
+		if string(data) == "[DONE]" {
+			return nil
+		}
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return err
+		}
+		if chunk.Model != "" {
+			res.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			res.Usage = *chunk.Usage
+		}
+		if chunk.XGroq != nil && chunk.XGroq.Usage != nil {
+			res.Usage = *chunk.XGroq.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				res.Content += choice.Delta.Content
+				onDelta(choice.Delta.Content)
+			}
+			for _, tc := range choice.Delta.ToolCalls {
+				call, ok := calls[tc.Index]
+				if !ok {
+					call = &openAIToolCall{}
+					calls[tc.Index] = call
+					order = append(order, tc.Index)
+				}
+				if tc.ID != "" {
+					call.ID = tc.ID
+				}
+				if tc.Function.Name != "" {
+					call.Function.Name = tc.Function.Name
+				}
+				call.Function.Arguments += tc.Function.Arguments
+			}
+			if choice.FinishReason != "" {
+				res.FinishReason = choice.FinishReason
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range order {
+		res.ToolCalls = append(res.ToolCalls, ToolCall{
+			ID:        calls[i].ID,
+			Name:      calls[i].Function.Name,
+			Arguments: json.RawMessage(calls[i].Function.Arguments),
+		})
+	}
+	return res, nil
+}
+
+func (c *OpenAI) headers() map[string]string {
+	return map[string]string{"Authorization": "Bearer " + c.APIKey}
+}
+
+func (c *OpenAI) buildRequest(req Request) openAIRequest {
+	oReq := openAIRequest{
+		Model:          req.Model,
+		Temperature:    req.Temperature,
+		TopP:           req.TopP,
+		MaxTokens:      req.MaxTokens,
+		ResponseFormat: req.ResponseFormat,
+	}
+	for _, t := range req.Tools {
+		oReq.Tools = append(oReq.Tools, openAITool{Type: "function", Function: t})
+	}
+	for _, m := range req.Messages {
+		oMsg := openAIMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		if len(m.Images) > 0 {
+			parts := []openAIContentPart{{Type: "text", Text: m.Content}}
+			for _, img := range m.Images {
+				parts = append(parts, openAIContentPart{Type: "image_url", ImageURL: map[string]string{"url": img}})
+			}
+			oMsg.Content = parts
+		}
+		for _, tc := range m.ToolCalls {
+			otc := openAIToolCall{ID: tc.ID, Type: "function"}
+			otc.Function.Name = tc.Name
+			otc.Function.Arguments = string(tc.Arguments)
+			oMsg.ToolCalls = append(oMsg.ToolCalls, otc)
+		}
+		oReq.Messages = append(oReq.Messages, oMsg)
+	}
+	return oReq
+}
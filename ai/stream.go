@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Streamer diimplementasi provider yang mendukung streaming token
+type Streamer interface {
+	Stream(ctx context.Context, req Request, onDelta func(text string)) (*Response, error)
+}
+
+// CompleteStream memakai Stream jika client mendukung streaming,
+// jika tidak memakai Complete lalu mengirim seluruh jawaban sebagai satu delta
+func CompleteStream(ctx context.Context, c LLMClient, req Request, onDelta func(text string)) (*Response, error) {
+	if s, ok := c.(Streamer); ok && onDelta != nil {
+		return s.Stream(ctx, req, onDelta)
+	}
+	resp, err := c.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if onDelta != nil && resp.Content != "" {
+		onDelta(resp.Content)
+	}
+	return resp, nil
+}
+
+// postJSON mengirim payload ke provider, response non-2xx dikembalikan sebagai error
+func postJSON(ctx context.Context, client *http.Client, provider, url string, headers map[string]string, payload any) (*http.Response, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s: status %d: %s", provider, resp.StatusCode, body)
+	}
+	return resp, nil
+}
+
+// readSSE membaca stream Server-Sent Events dan memanggil fn untuk setiap event
+func readSSE(r io.Reader, fn func(event string, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	event := ""
+	data := []string{}
+	flush := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := fn(event, []byte(strings.Join(data, "\n")))
+		event, data = "", data[:0]
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := flush(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
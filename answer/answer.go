@@ -0,0 +1,286 @@
+// Package answer berisi format jawaban terstruktur (blocks) yang dikirim ke client:
+// text, table, chart dan form. Jawaban model divalidasi di server sehingga client
+// cukup merender data, tanpa menjalankan HTML atau script buatan model.
+package answer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Jenis block
+const (
+	TypeText  = "text"
+	TypeTable = "table"
+	TypeChart = "chart"
+	TypeForm  = "form"
+)
+
+// Batas ukuran jawaban
+const (
+	MaxBlocks    = 20
+	MaxTableRows = 500
+	MaxSeries    = 10
+	MaxFields    = 30
+)
+
+var (
+	chartTypes = []string{"line", "spline", "area", "column", "bar", "pie"}
+	fieldTypes = []string{"text", "number", "email", "date", "textarea", "select", "checkbox"}
+	cellTypes  = []string{"", ColText, ColNumber, ColMoney, ColCurrency, ColDate}
+	fieldName  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\[\]]*$`)
+)
+
+// Answer bentuk JSON jawaban model
+type Answer struct {
+	Blocks []Block `json:"blocks"`
+}
+
+// Block satu bagian jawaban, hanya field sesuai Type yang diisi
+type Block struct {
+	Type  string `json:"type"`
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text,omitempty"`
+	Table *Table `json:"table,omitempty"`
+	Chart *Chart `json:"chart,omitempty"`
+	Form  *Form  `json:"form,omitempty"`
+}
+
+// Table isi block table, setiap cell berupa string, angka, boolean atau null.
+// Types (opsional) jenis setiap kolom, angka/uang/tanggal diformat server lewat Localize
+type Table struct {
+	Columns  []string `json:"columns"`
+	Types    []string `json:"types,omitempty"`
+	Currency string   `json:"currency,omitempty"` // mata uang kolom money jika tidak ada kolom currency
+	Rows     [][]any  `json:"rows"`
+}
+
+// Chart isi block chart, Categories menjadi sumbu x (atau label untuk pie)
+type Chart struct {
+	Type       string   `json:"type"`
+	Categories []string `json:"categories,omitempty"`
+	Series     []Series `json:"series"`
+}
+
+// Series satu seri data chart
+type Series struct {
+	Name string    `json:"name"`
+	Data []float64 `json:"data"`
+}
+
+// Form isi block form. Form dengan ID dan Action dikirim client sebagai JSON
+// {"form": ID, "values": {...}} ke Action, selain itu dikirim sebagai pesan chat
+type Form struct {
+	ID     string  `json:"id,omitempty"`
+	Action string  `json:"action,omitempty"`
+	Fields []Field `json:"fields"`
+	Submit string  `json:"submit,omitempty"`
+}
+
+// Field satu input pada form
+type Field struct {
+	Name     string   `json:"name"`
+	Label    string   `json:"label,omitempty"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Options  []string `json:"options,omitempty"`
+	Value    any      `json:"value,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Text membuat jawaban berisi satu block text
+func Text(text string) []Block {
+	return []Block{{Type: TypeText, Text: text}}
+}
+
+// Parse membaca jawaban model. Jawaban yang bukan JSON dianggap satu block text,
+// jawaban JSON wajib lolos Validate
+func Parse(content string) ([]Block, error) {
+	content = stripFence(content)
+	if content == "" {
+		return nil, fmt.Errorf("empty answer")
+	}
+
+	var blocks []Block
+	switch content[0] {
+	case '{':
+		var a Answer
+		if err := json.Unmarshal([]byte(content), &a); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+		blocks = a.Blocks
+	case '[':
+		if err := json.Unmarshal([]byte(content), &blocks); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+	default:
+		return Text(content), nil
+	}
+
+	if err := Validate(blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// Validate memeriksa struktur setiap block
+func Validate(blocks []Block) error {
+	errs := []string{}
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	if len(blocks) == 0 {
+		add("blocks is required")
+	}
+	if len(blocks) > MaxBlocks {
+		add("blocks: max %d blocks", MaxBlocks)
+	}
+
+	for i, b := range blocks {
+		p := fmt.Sprintf("blocks[%d]", i)
+		switch b.Type {
+		case TypeText:
+			if strings.TrimSpace(b.Text) == "" {
+				add("%s.text is required", p)
+			}
+		case TypeTable:
+			if b.Table == nil {
+				add("%s.table is required", p)
+				continue
+			}
+			if len(b.Table.Columns) == 0 {
+				add("%s.table.columns is required", p)
+			}
+			if len(b.Table.Types) > 0 && len(b.Table.Types) != len(b.Table.Columns) {
+				add("%s.table.types: expected %d types, got %d", p, len(b.Table.Columns), len(b.Table.Types))
+			}
+			for j, t := range b.Table.Types {
+				if !oneOf(t, cellTypes) {
+					add("%s.table.types[%d] must be one of %s", p, j, strings.Join(cellTypes[1:], ", "))
+				}
+			}
+			if len(b.Table.Rows) > MaxTableRows {
+				add("%s.table.rows: max %d rows", p, MaxTableRows)
+			}
+			for j, row := range b.Table.Rows {
+				if len(row) != len(b.Table.Columns) {
+					add("%s.table.rows[%d]: expected %d cells, got %d", p, j, len(b.Table.Columns), len(row))
+				}
+				for k, cell := range row {
+					switch cell.(type) {
+					case nil, string, float64, bool:
+					default:
+						add("%s.table.rows[%d][%d] must be a string, number, boolean or null", p, j, k)
+					}
+				}
+			}
+		case TypeChart:
+			if b.Chart == nil {
+				add("%s.chart is required", p)
+				continue
+			}
+			if !oneOf(b.Chart.Type, chartTypes) {
+				add("%s.chart.type must be one of %s", p, strings.Join(chartTypes, ", "))
+			}
+			if len(b.Chart.Series) == 0 || len(b.Chart.Series) > MaxSeries {
+				add("%s.chart.series must have 1 to %d series", p, MaxSeries)
+			}
+			for j, s := range b.Chart.Series {
+				if len(s.Data) == 0 {
+					add("%s.chart.series[%d].data is required", p, j)
+				}
+				if len(b.Chart.Categories) > 0 && len(s.Data) != len(b.Chart.Categories) {
+					add("%s.chart.series[%d].data: expected %d values, got %d", p, j, len(b.Chart.Categories), len(s.Data))
+				}
+			}
+		case TypeForm:
+			if b.Form == nil {
+				add("%s.form is required", p)
+				continue
+			}
+			if b.Form.Action != "" && b.Form.Action != FormAction {
+				add("%s.form.action must be empty or %s", p, FormAction)
+			}
+			if len(b.Form.Fields) == 0 || len(b.Form.Fields) > MaxFields {
+				add("%s.form.fields must have 1 to %d fields", p, MaxFields)
+			}
+			for j, f := range b.Form.Fields {
+				if !fieldName.MatchString(f.Name) {
+					add("%s.form.fields[%d].name %q is not a valid field name", p, j, f.Name)
+				}
+				if !oneOf(f.Type, fieldTypes) {
+					add("%s.form.fields[%d].type must be one of %s", p, j, strings.Join(fieldTypes, ", "))
+				}
+				if f.Type == "select" && len(f.Options) == 0 {
+					add("%s.form.fields[%d].options is required for select", p, j)
+				}
+			}
+		default:
+			add("%s.type must be one of text, table, chart, form", p)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// PlainText versi teks jawaban untuk client yang tidak merender blocks
+func PlainText(blocks []Block) string {
+	parts := []string{}
+	for _, b := range blocks {
+		switch b.Type {
+		case TypeText:
+			parts = append(parts, b.Text)
+		case TypeTable:
+			lines := []string{}
+			if b.Title != "" {
+				lines = append(lines, b.Title)
+			}
+			lines = append(lines, strings.Join(b.Table.Columns, " | "))
+			for _, row := range b.Table.Rows {
+				cells := make([]string, len(row))
+				for i, c := range row {
+					if c != nil {
+						cells[i] = fmt.Sprint(c)
+					}
+				}
+				lines = append(lines, strings.Join(cells, " | "))
+			}
+			parts = append(parts, strings.Join(lines, "\n"))
+		default:
+			if b.Title != "" {
+				parts = append(parts, b.Title)
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// stripFence membuang pembungkus markdown ```json ... ``` dari jawaban model
+func stripFence(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "```") {
+		s = strings.TrimPrefix(s, "```")
+		if i := strings.IndexByte(s, '\n'); i >= 0 {
+			s = s[i+1:]
+		} else {
+			s = strings.TrimPrefix(s, "json")
+		}
+		s = strings.TrimSuffix(strings.TrimSpace(s), "```")
+	}
+	return strings.TrimSpace(s)
+}
+
+func oneOf(v string, list []string) bool {
+	for _, l := range list {
+		if v == l {
+			return true
+		}
+	}
+	return false
+}
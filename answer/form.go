@@ -0,0 +1,70 @@
+package answer
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FormAction endpoint untuk mengirim isi form sebagai JSON
+const FormAction = "/webhook/form"
+
+// FormFor membuat form dari struct payload (atau pointer ke struct). Nama field diambil dari tag json,
+// label dari tag label, required/email/oneof dari tag validate dan tipe input dari tipe field
+// (tag form bisa mengganti tipe, misal form:"textarea"). values mengisi nilai awal dan errs
+// berisi pesan error per nama field
+func FormFor(id string, v any, values map[string]any, errs map[string]string) *Form {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	form := &Form{ID: id, Action: FormAction, Submit: "Simpan"}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.SplitN(sf.Tag.Get("json"), ",", 2)[0]
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		f := Field{Name: name, Label: sf.Tag.Get("label"), Type: inputType(sf.Type), Value: values[name], Error: errs[name]}
+		if f.Label == "" {
+			f.Label = name
+		}
+		for _, rule := range strings.Split(sf.Tag.Get("validate"), ",") {
+			rule, param, _ := strings.Cut(rule, "=")
+			switch rule {
+			case "required":
+				f.Required = true
+			case "email":
+				f.Type = "email"
+			case "oneof":
+				f.Type = "select"
+				f.Options = strings.Fields(param)
+			}
+		}
+		if tag := sf.Tag.Get("form"); tag != "" {
+			f.Type = tag
+		}
+		form.Fields = append(form.Fields, f)
+	}
+	return form
+}
+
+func inputType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "checkbox"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "text"
+	}
+}
@@ -0,0 +1,62 @@
+package answer
+
+// Jenis kolom table
+const (
+	ColText     = "text"
+	ColNumber   = "number"
+	ColMoney    = "money"    // nominal, mata uang dari kolom currency atau Table.Currency
+	ColCurrency = "currency" // kode mata uang baris tersebut, misal IDR atau USD
+	ColDate     = "date"     // tanggal YYYY-MM-DD
+)
+
+// Formatter format angka, uang dan tanggal sesuai pengaturan tenant
+type Formatter interface {
+	Number(v float64) string
+	Money(v float64, currency string) string
+	Date(v string) string
+}
+
+// Localize mengubah cell angka, uang dan tanggal pada table yang punya Types menjadi teks
+// sesuai f. Cell yang sudah berupa teks dibiarkan
+func Localize(blocks []Block, f Formatter) []Block {
+	for _, b := range blocks {
+		if b.Type != TypeTable || b.Table == nil || len(b.Table.Types) != len(b.Table.Columns) {
+			continue
+		}
+		t := b.Table
+		curCol := -1
+		for i, typ := range t.Types {
+			if typ == ColCurrency {
+				curCol = i
+				break
+			}
+		}
+		for _, row := range t.Rows {
+			currency := t.Currency
+			if curCol >= 0 && curCol < len(row) {
+				if c, ok := row[curCol].(string); ok && c != "" {
+					currency = c
+				}
+			}
+			for i, cell := range row {
+				if i >= len(t.Types) {
+					break
+				}
+				switch v := cell.(type) {
+				case float64:
+					switch t.Types[i] {
+					case ColNumber:
+						row[i] = f.Number(v)
+					case ColMoney:
+						row[i] = f.Money(v, currency)
+					}
+				case string:
+					if t.Types[i] == ColDate {
+						row[i] = f.Date(v)
+					}
+				}
+			}
+		}
+	}
+	return blocks
+}
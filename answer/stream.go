@@ -0,0 +1,167 @@
+package answer
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// TextStream meneruskan isi field "text" dari jawaban JSON yang sedang di-stream,
+// sehingga client bisa menampilkan teks selagi model menyusun blocks.
+// Jika jawaban ternyata bukan JSON, seluruh teks diteruskan apa adanya
+type TextStream struct {
+	onText func(string)
+
+	mode    int // 0 belum diketahui, 1 JSON, 2 teks biasa
+	fence   bool
+	emitted bool
+
+	inString    bool
+	isValue     bool
+	expectValue bool
+	emitting    bool
+	escape      bool
+	inHex       bool
+	hex         []rune
+	surrogate   rune
+	str         strings.Builder
+	lastKey     string
+}
+
+const (
+	modeUnknown = iota
+	modeJSON
+	modeRaw
+)
+
+// NewTextStream membuat TextStream yang memanggil onText untuk setiap potongan teks
+func NewTextStream(onText func(string)) *TextStream {
+	return &TextStream{onText: onText}
+}
+
+// Write membaca satu potongan jawaban dari model
+func (s *TextStream) Write(chunk string) {
+	out := strings.Builder{}
+	for _, r := range chunk {
+		switch s.mode {
+		case modeUnknown:
+			s.detect(r, &out)
+		case modeRaw:
+			out.WriteRune(r)
+		case modeJSON:
+			s.scan(r, &out)
+		}
+	}
+	if out.Len() > 0 {
+		s.emitted = true
+		s.onText(out.String())
+	}
+}
+
+// detect menentukan mode dari karakter pertama, pembungkus ```json dilewati
+func (s *TextStream) detect(r rune, out *strings.Builder) {
+	switch {
+	case s.fence:
+		if r == '\n' {
+			s.fence = false
+		}
+	case r == ' ' || r == '\n' || r == '\r' || r == '\t':
+	case r == '`':
+		s.fence = true
+	case r == '{' || r == '[':
+		s.mode = modeJSON
+	default:
+		s.mode = modeRaw
+		out.WriteRune(r)
+	}
+}
+
+func (s *TextStream) scan(r rune, out *strings.Builder) {
+	if !s.inString {
+		switch r {
+		case '"':
+			s.inString = true
+			s.isValue = s.expectValue
+			s.emitting = s.isValue && s.lastKey == "text"
+			s.str.Reset()
+			if s.emitting && (s.emitted || out.Len() > 0) {
+				out.WriteString("\n\n")
+			}
+		case ':':
+			s.expectValue = true
+		case ' ', '\n', '\r', '\t':
+		default:
+			s.expectValue = false
+		}
+		return
+	}
+
+	if s.inHex || (s.escape && r == 'u') {
+		s.readUnicode(r, out)
+		return
+	}
+	if s.escape {
+		s.escape = false
+		switch r {
+		case 'n':
+			r = '\n'
+		case 't':
+			r = '\t'
+		case 'r':
+			r = '\r'
+		case 'b', 'f':
+			return
+		}
+		s.write(r, out)
+		return
+	}
+
+	switch r {
+	case '\\':
+		s.escape = true
+	case '"':
+		s.inString = false
+		s.expectValue = false
+		if !s.isValue {
+			s.lastKey = s.str.String()
+		}
+		s.emitting = false
+	default:
+		s.write(r, out)
+	}
+}
+
+// readUnicode membaca escape \uXXXX, termasuk pasangan surrogate
+func (s *TextStream) readUnicode(r rune, out *strings.Builder) {
+	if s.escape {
+		s.escape, s.inHex, s.hex = false, true, s.hex[:0]
+		return
+	}
+	s.hex = append(s.hex, r)
+	if len(s.hex) < 4 {
+		return
+	}
+	n, err := strconv.ParseUint(string(s.hex), 16, 32)
+	s.inHex = false
+	if err != nil {
+		return
+	}
+	c := rune(n)
+	switch {
+	case utf16.IsSurrogate(c) && s.surrogate == 0:
+		s.surrogate = c
+		return
+	case s.surrogate != 0:
+		c = utf16.DecodeRune(s.surrogate, c)
+		s.surrogate = 0
+	}
+	s.write(c, out)
+}
+
+func (s *TextStream) write(r rune, out *strings.Builder) {
+	if s.emitting {
+		out.WriteRune(r)
+	} else {
+		s.str.WriteRune(r)
+	}
+}
@@ -0,0 +1,121 @@
+// Package auth memverifikasi pemanggil bot. Token Zahir dicek secara lokal (format dan masa berlaku
+// JWT) lalu ke Zahir API, hasilnya di-cache per token + slug. Setiap pemanggil mendapat role yang
+// menentukan aksi apa saja yang boleh dijalankan.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var (
+	ErrUnauthenticated = errors.New("token Zahir wajib diisi")
+	ErrInvalidToken    = errors.New("token Zahir tidak valid atau sudah kedaluwarsa")
+)
+
+// Role hak akses pemanggil, urut dari yang paling terbatas
+type Role string
+
+const (
+	Viewer Role = "viewer" // hanya membaca data
+	Editor Role = "editor" // membaca, menambah, mengubah dan menghapus data setelah konfirmasi
+	Admin  Role = "admin"  // editor ditambah mengubah pengaturan tenant
+)
+
+// Permission aksi yang dibatasi role
+type Permission int
+
+const (
+	Read Permission = iota
+	Write
+	ManageSettings
+)
+
+// minRole role minimal untuk setiap permission
+var minRole = map[Permission]Role{Read: Viewer, Write: Editor, ManageSettings: Admin}
+
+var rank = map[Role]int{Viewer: 1, Editor: 2, Admin: 3}
+
+// ParseRole memvalidasi nama role
+func ParseRole(s string) (Role, error) {
+	if _, ok := rank[Role(s)]; !ok {
+		return "", fmt.Errorf("role %q tidak dikenal, gunakan viewer, editor atau admin", s)
+	}
+	return Role(s), nil
+}
+
+// Can true jika role boleh menjalankan p
+func (r Role) Can(p Permission) bool {
+	return rank[r] > 0 && rank[r] >= rank[minRole[p]]
+}
+
+// Principal pemanggil yang sudah terverifikasi beserta kredensial Zahir-nya
+type Principal struct {
+	BearerToken string `json:"-"`
+	Slug        string `json:"slug"`
+	User        string `json:"user,omitempty"` // dari klaim token (email, preferred_username atau sub)
+	Role        Role   `json:"role"`
+}
+
+// Can true jika principal ada dan role-nya boleh menjalankan p
+func (p *Principal) Can(perm Permission) bool {
+	return p != nil && p.Role.Can(perm)
+}
+
+type contextKey struct{}
+
+// NewContext menyimpan principal di context request
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext principal dari context, nil jika request belum diautentikasi
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(contextKey{}).(*Principal)
+	return p
+}
+
+// Roles role per slug lalu per user, key "*" berlaku untuk semua slug atau semua user:
+//
+//	{"toko-abc": {"*": "viewer", "budi@tokoabc.com": "admin"}, "*": {"*": "editor"}}
+type Roles map[string]map[string]Role
+
+// LoadRoles membaca file JSON Roles, path kosong berarti tanpa aturan per user
+func LoadRoles(path string) (Roles, error) {
+	roles := Roles{}
+	if path == "" {
+		return roles, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &roles); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for slug, users := range roles {
+		for user, role := range users {
+			if _, err := ParseRole(string(role)); err != nil {
+				return nil, fmt.Errorf("%s: %s.%s: %w", path, slug, user, err)
+			}
+		}
+	}
+	return roles, nil
+}
+
+// Role role user di slug, dari aturan yang paling spesifik sampai def
+func (r Roles) Role(slug, user string, def Role) Role {
+	for _, s := range []string{slug, "*"} {
+		users := r[s]
+		if role, ok := users[user]; ok && user != "" {
+			return role
+		}
+		if role, ok := users["*"]; ok {
+			return role
+		}
+	}
+	return def
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLoginNotFound session login tidak ada, sudah logout atau kedaluwarsa
+var ErrLoginNotFound = errors.New("session login tidak ditemukan atau sudah kedaluwarsa, silakan login ulang")
+
+// Logins session login: token Zahir dan slug disimpan terenkripsi AES-GCM di Store,
+// client hanya memegang ID session (cookie). Store hanya melihat hash ID dan ciphertext
+type Logins struct {
+	store Store
+	aead  cipher.AEAD
+	ttl   time.Duration
+}
+
+// Login isi session login setelah didekripsi
+type Login struct {
+	BearerToken string    `json:"bearer_token"`
+	Slug        string    `json:"slug"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// NewLogins membuat session login, key AES 16, 24 atau 32 byte
+func NewLogins(store Store, key []byte, ttl time.Duration) (*Logins, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("kunci enkripsi login: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Logins{store: store, aead: aead, ttl: ttl}, nil
+}
+
+// ParseKey membaca kunci enkripsi dalam base64 atau hex
+func ParseKey(s string) ([]byte, error) {
+	if key, err := hex.DecodeString(s); err == nil {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("kunci harus berupa hex atau base64")
+}
+
+// Create menyimpan kredensial dan mengembalikan ID session baru
+func (l *Logins) Create(ctx context.Context, bearerToken, slug string) (string, *Login, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	id := base64.RawURLEncoding.EncodeToString(b)
+	login := &Login{BearerToken: bearerToken, Slug: slug, ExpiresAt: time.Now().Add(l.ttl)}
+
+	plain, err := json.Marshal(login)
+	if err != nil {
+		return "", nil, err
+	}
+	key := storeKey(id)
+	nonce := make([]byte, l.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	// key dipakai sebagai additional data, ciphertext tidak bisa dipindah ke key lain
+	sealed := l.aead.Seal(nonce, nonce, plain, []byte(key))
+	if err := l.store.Set(ctx, key, sealed, l.ttl); err != nil {
+		return "", nil, err
+	}
+	return id, login, nil
+}
+
+// Get mendekripsi session login berdasarkan ID dari cookie
+func (l *Logins) Get(ctx context.Context, id string) (*Login, error) {
+	if id == "" {
+		return nil, ErrLoginNotFound
+	}
+	key := storeKey(id)
+	sealed, err := l.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	n := l.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, ErrLoginNotFound
+	}
+	plain, err := l.aead.Open(nil, sealed[:n], sealed[n:], []byte(key))
+	if err != nil {
+		// kunci diganti atau data rusak, session dianggap tidak ada
+		return nil, ErrLoginNotFound
+	}
+	login := &Login{}
+	if err := json.Unmarshal(plain, login); err != nil {
+		return nil, ErrLoginNotFound
+	}
+	if time.Now().After(login.ExpiresAt) {
+		l.store.Delete(ctx, key)
+		return nil, ErrLoginNotFound
+	}
+	return login, nil
+}
+
+// Delete menghapus session login (logout)
+func (l *Logins) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return nil
+	}
+	return l.store.Delete(ctx, storeKey(id))
+}
+
+// storeKey ID session tidak disimpan mentah, bocornya isi store tidak memberi cookie yang valid
+func storeKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store penyimpanan session login yang sudah terenkripsi, key berupa hash hex ID session
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error) // ErrLoginNotFound jika tidak ada atau kedaluwarsa
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+type stored struct {
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MemoryStore session login di memory, hilang saat server restart
+type MemoryStore struct {
+	mu    sync.Mutex
+	items map[string]stored
+}
+
+// NewMemoryStore membuat store di memory
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: map[string]stored{}}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	it, ok := s.items[key]
+	if !ok || time.Now().After(it.ExpiresAt) {
+		delete(s.items, key)
+		return nil, ErrLoginNotFound
+	}
+	return it.Value, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, it := range s.items {
+		if now.After(it.ExpiresAt) {
+			delete(s.items, k)
+		}
+	}
+	s.items[key] = stored{Value: value, ExpiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return nil
+}
+
+// FileStore session login sebagai satu file JSON per key di dir, tetap ada setelah restart
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore membuat store di dir, dir dibuat jika belum ada
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Get(_ context.Context, key string) ([]byte, error) {
+	b, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrLoginNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	it := stored{}
+	if err := json.Unmarshal(b, &it); err != nil || time.Now().After(it.ExpiresAt) {
+		os.Remove(s.path(key))
+		return nil, ErrLoginNotFound
+	}
+	return it.Value, nil
+}
+
+// Set menulis ke file sementara lalu rename, file kedaluwarsa lain ikut dibuang
+func (s *FileStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.sweep()
+	b, err := json.Marshal(stored{Value: value, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".login-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.Base(key)+".json")
+}
+
+// sweep membuang file session yang sudah kedaluwarsa
+func (s *FileStore) sweep() {
+	files, _ := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	now := time.Now()
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		it := stored{}
+		if json.Unmarshal(b, &it) == nil && now.After(it.ExpiresAt) {
+			os.Remove(f)
+		}
+	}
+}
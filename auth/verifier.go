@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Introspector memeriksa token ke Zahir API, ErrInvalidToken jika Zahir menolak token untuk slug
+type Introspector func(ctx context.Context, bearerToken, slug string) error
+
+// Verifier memverifikasi token Zahir dan memberi role, token valid di-cache selama ttl
+type Verifier struct {
+	introspect  Introspector
+	roles       Roles
+	defaultRole Role
+	ttl         time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+type cached struct {
+	principal Principal
+	expiresAt time.Time
+}
+
+// NewVerifier membuat verifier, defaultRole dipakai untuk user yang tidak ada di roles
+func NewVerifier(introspect Introspector, roles Roles, defaultRole Role, ttl time.Duration) *Verifier {
+	return &Verifier{introspect: introspect, roles: roles, defaultRole: defaultRole, ttl: ttl, cache: map[string]cached{}}
+}
+
+// Verify memeriksa token untuk slug. Token JWT yang sudah kedaluwarsa ditolak tanpa memanggil Zahir,
+// selain itu token dicek ke Zahir kecuali masih ada di cache
+func (v *Verifier) Verify(ctx context.Context, bearerToken, slug string) (*Principal, error) {
+	if bearerToken == "" || slug == "" {
+		return nil, ErrUnauthenticated
+	}
+	claims, err := parseClaims(bearerToken)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if claims.expiresAt != nil && !now.Before(*claims.expiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	key := cacheKey(bearerToken, slug)
+	v.mu.Lock()
+	c, ok := v.cache[key]
+	v.mu.Unlock()
+	if ok && now.Before(c.expiresAt) {
+		p := c.principal
+		return &p, nil
+	}
+
+	if err := v.introspect(ctx, bearerToken, slug); err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("gagal memverifikasi token ke Zahir: %w", err)
+	}
+
+	p := Principal{BearerToken: bearerToken, Slug: slug, User: claims.user, Role: v.roles.Role(slug, claims.user, v.defaultRole)}
+	expiresAt := now.Add(v.ttl)
+	if claims.expiresAt != nil && claims.expiresAt.Before(expiresAt) {
+		expiresAt = *claims.expiresAt
+	}
+	v.mu.Lock()
+	v.sweep(now)
+	v.cache[key] = cached{principal: p, expiresAt: expiresAt}
+	v.mu.Unlock()
+	return &p, nil
+}
+
+// sweep membuang cache yang sudah kedaluwarsa, dipanggil dengan mu terkunci
+func (v *Verifier) sweep(now time.Time) {
+	for key, c := range v.cache {
+		if !now.Before(c.expiresAt) {
+			delete(v.cache, key)
+		}
+	}
+}
+
+// cacheKey token tidak disimpan mentah sebagai key cache
+func cacheKey(bearerToken, slug string) string {
+	sum := sha256.Sum256([]byte(bearerToken + "|" + slug))
+	return hex.EncodeToString(sum[:])
+}
+
+type claims struct {
+	user      string
+	expiresAt *time.Time
+}
+
+// parseClaims membaca payload token JWT tanpa memeriksa signature (itu tugas Zahir).
+// Token yang bukan JWT dianggap token opaque tanpa klaim
+func parseClaims(token string) (claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims{}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return claims{}, ErrInvalidToken
+	}
+	var payload struct {
+		Exp               json.Number `json:"exp"`
+		Sub               string      `json:"sub"`
+		Email             string      `json:"email"`
+		PreferredUsername string      `json:"preferred_username"`
+	}
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return claims{}, ErrInvalidToken
+	}
+
+	c := claims{}
+	for _, u := range []string{payload.Email, payload.PreferredUsername, payload.Sub} {
+		if u != "" {
+			c.user = u
+			break
+		}
+	}
+	if payload.Exp != "" {
+		exp, err := payload.Exp.Float64()
+		if err != nil {
+			return claims{}, ErrInvalidToken
+		}
+		t := time.Unix(int64(exp), 0)
+		c.expiresAt = &t
+	}
+	return c, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MaulanaR/zai/answer"
+	"github.com/MaulanaR/zai/session"
+)
+
+// clarification pertanyaan yang menunggu jawaban user, disimpan di session
+type clarification struct {
+	Message  string // pesan user yang memicu pertanyaan
+	Clarify  Clarify
+	Decision *APIDecision // dilanjutkan setelah Slot terisi, nil jika pertanyaan berasal dari model
+}
+
+// clarify menyimpan pertanyaan di session dan mengembalikannya ke user tanpa memanggil Zahir
+func (bot *ChatBot) clarify(sess *session.Session, message string, c *Clarify, decision *APIDecision) *ZahirResponse {
+	sess.SetPending(&clarification{Message: message, Clarify: *c, Decision: decision})
+	return &ZahirResponse{
+		Status:  "OK",
+		Message: c.Question,
+		Blocks:  answer.Text(c.Question),
+		Clarify: c,
+	}
+}
+
+// resumeClarification mengisi slot keputusan yang tertunda dengan jawaban user. Hasilnya pesan untuk agent
+// dan keputusan yang langsung dijalankan (nil jika agent harus memutuskan ulang)
+func (bot *ChatBot) resumeClarification(sess *session.Session, reply string) (string, *APIDecision) {
+	c, ok := sess.TakePending().(*clarification)
+	if !ok {
+		return reply, nil
+	}
+
+	if c.Decision != nil && c.Clarify.Slot != "" {
+		choice, ok := matchChoice(reply, c.Clarify.Choices)
+		if !ok {
+			// bukan jawaban atas pertanyaan, anggap permintaan baru
+			return reply, nil
+		}
+		c.Decision.Params[c.Clarify.Slot] = choice
+		delete(c.Decision.Entities, c.Clarify.Slot)
+		return c.Message, c.Decision
+	}
+	return fmt.Sprintf("%s\n%s %s", c.Message, c.Clarify.Question, reply), nil
+}
+
+// matchChoice mencocokkan jawaban dengan pilihan: teks sama, nomor urut, atau satu-satunya pilihan yang memuat jawaban
+func matchChoice(reply string, choices []string) (string, bool) {
+	reply = strings.TrimSpace(strings.Trim(strings.TrimSpace(reply), ".!?"))
+	if reply == "" {
+		return "", false
+	}
+	for _, c := range choices {
+		if strings.EqualFold(c, reply) {
+			return c, true
+		}
+	}
+	if n, err := strconv.Atoi(reply); err == nil && n >= 1 && n <= len(choices) {
+		return choices[n-1], true
+	}
+
+	found := ""
+	for _, c := range choices {
+		if strings.Contains(strings.ToLower(c), strings.ToLower(reply)) {
+			if found != "" {
+				return "", false
+			}
+			found = c
+		}
+	}
+	return found, found != ""
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// query param yang bukan filter field
+var reservedParams = map[string]bool{
+	"page":     true,
+	"per_page": true,
+	"sort":     true,
+}
+
+var opParam = regexp.MustCompile(`^(.+)\[\$(eq|ne|gt|gte|lt|lte|like|ilike)\]$`)
+
+// condition satu filter field, misal date[$gte]=2026-01-01
+type condition struct {
+	field string
+	op    string
+	value string
+}
+
+func parseConditions(q url.Values) []condition {
+	conds := []condition{}
+	for key, vals := range q {
+		if reservedParams[key] || strings.HasPrefix(key, "includes[") || len(vals) == 0 {
+			continue
+		}
+		c := condition{field: key, op: "eq", value: vals[0]}
+		if m := opParam.FindStringSubmatch(key); m != nil {
+			c.field, c.op = m[1], m[2]
+		}
+		conds = append(conds, c)
+	}
+	return conds
+}
+
+// match mengecek record (sudah di-flatten) terhadap semua kondisi
+func match(flat map[string]any, conds []condition) bool {
+	for _, c := range conds {
+		v, ok := flat[c.field]
+		if !ok || v == nil {
+			if c.op == "ne" {
+				continue
+			}
+			return false
+		}
+		if !compare(v, c.op, c.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func compare(v any, op, want string) bool {
+	switch op {
+	case "like", "ilike":
+		got := fmt.Sprint(v)
+		pattern := strings.Trim(want, "%")
+		if op == "ilike" {
+			got, pattern = strings.ToLower(got), strings.ToLower(pattern)
+		}
+		return strings.Contains(got, pattern)
+	}
+
+	cmp := 0
+	switch x := v.(type) {
+	case float64:
+		w, err := strconv.ParseFloat(want, 64)
+		if err != nil {
+			return false
+		}
+		switch {
+		case x < w:
+			cmp = -1
+		case x > w:
+			cmp = 1
+		}
+	case bool:
+		if op != "eq" && op != "ne" {
+			return false
+		}
+		if strconv.FormatBool(x) != strings.ToLower(want) {
+			cmp = 1
+		}
+	default:
+		got := fmt.Sprint(x)
+		// field time dibandingkan dengan tanggal saja jika filter berupa tanggal
+		if _, err := time.Parse("2006-01-02", want); err == nil && len(got) > len(want) {
+			got = got[:len(want)]
+		}
+		if op == "eq" || op == "ne" {
+			got, want = strings.ToLower(got), strings.ToLower(want)
+		}
+		cmp = strings.Compare(got, want)
+	}
+
+	switch op {
+	case "eq":
+		return cmp == 0
+	case "ne":
+		return cmp != 0
+	case "gt":
+		return cmp > 0
+	case "gte":
+		return cmp >= 0
+	case "lt":
+		return cmp < 0
+	case "lte":
+		return cmp <= 0
+	}
+	return false
+}
+
+// flatten mengubah {"customer": {"name": "x"}} menjadi {"customer.name": "x"}, array dibiarkan
+func flatten(prefix string, in map[string]any, out map[string]any) map[string]any {
+	for k, v := range in {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]any); ok {
+			flatten(key, nested, out)
+			continue
+		}
+		out[key] = v
+	}
+	return out
+}
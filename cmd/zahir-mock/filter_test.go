@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		got  any
+		op   string
+		want string
+		ok   bool
+	}{
+		{"Budi Santoso", "eq", "Budi Santo", false},
+		{"Budi Santoso", "eq", "budi santoso", true},
+		{"Budi Santoso", "ne", "Budi Santo", true},
+		{"Budi Santoso", "like", "%Santo%", true},
+		{"2026-03-05T10:00:00Z", "eq", "2026-03-05", true},
+		{"2026-03-05T10:00:00Z", "lte", "2026-03-05", true},
+		{"2026-03-05T10:00:00Z", "gt", "2026-03-05", false},
+		{"2026-03-05", "gte", "2026-03-01", true},
+		{float64(240), "gt", "100", true},
+		{true, "eq", "true", true},
+	}
+	for _, tt := range tests {
+		if got := compare(tt.got, tt.op, tt.want); got != tt.ok {
+			t.Errorf("compare(%v, %s, %q) = %v, want %v", tt.got, tt.op, tt.want, got, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+// Command zahir-mock menjalankan Zahir API tiruan dengan data fixture,
+// supaya seluruh pipeline bot bisa dijalankan tanpa token Zahir asli.
+//
+//	go run ./cmd/zahir-mock -addr :8992
+//	ZAHIR_BASE_URL=http://127.0.0.1:8992/api/v2 go run .
+package main
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+const prefix = "/api/v2/"
+
+// endpoint list yang datanya bisa difilter, ditambah lewat POST dan diubah/dihapus lewat {endpoint}/{id}
+var collections = []string{"contacts", "products", "sales_invoices", "purchases_invoices"}
+
+// endpoint dashboard, dilayani apa adanya dari fixture
+var dashboards = map[string]string{
+	"dashboards/profit_loss_simple":   "profit_loss_simple.json",
+	"dashboards/balance_sheet_simple": "balance_sheet_simple.json",
+	"dashboards/daily_sales":          "daily_sales.json",
+}
+
+type store struct {
+	mu   sync.Mutex
+	data map[string][]map[string]any
+}
+
+func loadStore() (*store, error) {
+	st := &store{data: map[string][]map[string]any{}}
+	for _, name := range collections {
+		b, err := fixtures.ReadFile("fixtures/" + name + ".json")
+		if err != nil {
+			return nil, err
+		}
+		rows := []map[string]any{}
+		if err := json.Unmarshal(b, &rows); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		st.data[name] = rows
+	}
+	return st, nil
+}
+
+func main() {
+	addr := flag.String("addr", ":8992", "listen address")
+	flag.Parse()
+
+	st, err := loadStore()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	http.HandleFunc(prefix, st.handle)
+
+	log.Printf("Zahir mock listening on %s, base URL http://127.0.0.1%s%s", *addr, *addr, strings.TrimSuffix(prefix, "/"))
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func (st *store) handle(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s", r.Method, r.URL.RequestURI())
+
+	// token apapun diterima, tapi header wajib ada seperti API asli
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") || r.Header.Get("slug") == "" {
+		writeError(w, http.StatusUnauthorized, "missing bearer token or slug", nil)
+		return
+	}
+	// token berawalan "invalid" ditolak, untuk mencoba verifikasi token di bot
+	if strings.HasPrefix(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), "invalid") {
+		writeError(w, http.StatusUnauthorized, "invalid token", nil)
+		return
+	}
+
+	endpoint := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if file, ok := dashboards[endpoint]; ok && r.Method == http.MethodGet {
+		b, _ := fixtures.ReadFile("fixtures/" + file)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+		return
+	}
+
+	// contacts/{id}, products/{id}, dst untuk GET satu data, PATCH, PUT dan DELETE
+	if name, id, ok := strings.Cut(endpoint, "/"); ok && isCollection(name) {
+		st.item(w, r, name, id)
+		return
+	}
+
+	if !isCollection(endpoint) {
+		writeError(w, http.StatusNotFound, "endpoint not found", nil)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		st.list(w, r, endpoint)
+	case http.MethodPost:
+		st.create(w, r, endpoint)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+func (st *store) list(w http.ResponseWriter, r *http.Request, endpoint string) {
+	q := r.URL.Query()
+	conds := parseConditions(q)
+
+	st.mu.Lock()
+	rows := []map[string]any{}
+	for _, row := range st.data[endpoint] {
+		if match(flatten("", row, map[string]any{}), conds) {
+			rows = append(rows, row)
+		}
+	}
+	st.mu.Unlock()
+
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+	if perPage <= 0 {
+		perPage = 10
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	totalPages := (len(rows) + perPage - 1) / perPage
+
+	results := []map[string]any{}
+	for i := (page - 1) * perPage; i < len(rows) && i < page*perPage; i++ {
+		row := rows[i]
+		// line_items hanya dikirim jika diminta lewat includes[line_items]=true
+		if _, ok := row["line_items"]; ok && q.Get("includes[line_items]") != "true" {
+			row = copyWithout(row, "line_items")
+		}
+		results = append(results, row)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"count":       len(rows),
+		"page":        page,
+		"per_page":    perPage,
+		"total_pages": totalPages,
+		"results":     results,
+	})
+}
+
+func (st *store) create(w http.ResponseWriter, r *http.Request, endpoint string) {
+	row := map[string]any{}
+	if err := json.NewDecoder(r.Body).Decode(&row); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body", nil)
+		return
+	}
+
+	if detail := validate(endpoint, row); len(detail) > 0 {
+		writeError(w, http.StatusBadRequest, "validation error", detail)
+		return
+	}
+
+	row["id"] = newID()
+	st.mu.Lock()
+	st.data[endpoint] = append(st.data[endpoint], row)
+	st.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]any{"results": row})
+}
+
+func (st *store) item(w http.ResponseWriter, r *http.Request, endpoint, id string) {
+	input := map[string]any{}
+	if r.Method == http.MethodPatch || r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body", nil)
+			return
+		}
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	rows := st.data[endpoint]
+	i := -1
+	for j, row := range rows {
+		if row["id"] == id {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "data not found", nil)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"results": rows[i]})
+	case http.MethodPatch, http.MethodPut:
+		row := input
+		if r.Method == http.MethodPatch {
+			row = copyWithout(rows[i], "")
+			for k, v := range input {
+				row[k] = v
+			}
+		}
+		if detail := validate(endpoint, row); len(detail) > 0 {
+			writeError(w, http.StatusBadRequest, "validation error", detail)
+			return
+		}
+		row["id"] = id
+		rows[i] = row
+		writeJSON(w, http.StatusOK, map[string]any{"results": row})
+	case http.MethodDelete:
+		st.data[endpoint] = append(rows[:i:i], rows[i+1:]...)
+		writeJSON(w, http.StatusOK, map[string]any{"results": rows[i]})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// validate aturan minimal per endpoint, dikembalikan per field seperti API asli
+func validate(endpoint string, row map[string]any) map[string]string {
+	detail := map[string]string{}
+	required := func(fields ...string) {
+		flat := flatten("", row, map[string]any{})
+		for _, f := range fields {
+			if v, ok := flat[f]; !ok || v == nil || v == "" {
+				detail[f] = "required"
+			}
+		}
+	}
+
+	switch endpoint {
+	case "contacts":
+		required("name")
+		if email, ok := row["email"].(string); ok && email != "" {
+			if _, err := mail.ParseAddress(email); err != nil {
+				detail["email"] = "invalid email"
+			}
+		}
+	case "products":
+		required("name")
+		for _, f := range []string{"price", "unit_price"} {
+			if v, ok := row[f]; ok {
+				if n, isNum := v.(float64); !isNum || n < 0 {
+					detail[f] = "must be a positive number"
+				}
+			}
+		}
+	case "sales_invoices":
+		required("customer.id", "date")
+		if items, _ := row["line_items"].([]any); len(items) == 0 {
+			detail["line_items"] = "required"
+		}
+	case "purchases_invoices":
+		required("supplier.id", "date")
+		if items, _ := row["line_items"].([]any); len(items) == 0 {
+			detail["line_items"] = "required"
+		}
+	}
+	return detail
+}
+
+func isCollection(endpoint string) bool {
+	for _, c := range collections {
+		if c == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+func copyWithout(row map[string]any, key string) map[string]any {
+	res := make(map[string]any, len(row))
+	for k, v := range row {
+		if k != key {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string, detail any) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{
+			"code":    status,
+			"message": message,
+			"detail":  detail,
+		},
+	})
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MaulanaR/zai/action"
+	"github.com/MaulanaR/zai/answer"
+	"github.com/MaulanaR/zai/auth"
+	"github.com/MaulanaR/zai/model"
+	"github.com/MaulanaR/zai/session"
+	"github.com/MaulanaR/zai/validation"
+)
+
+// inputSchema payload create yang bisa diisi lewat chat maupun form
+type inputSchema struct {
+	Endpoint string
+	Label    string
+	New      func() any
+}
+
+// inputSchemas daftar payload create, key juga dipakai sebagai ID form
+var inputSchemas = map[string]inputSchema{
+	"contacts": {Endpoint: "contacts", Label: "kontak", New: func() any { return &model.CreateContactInput{} }},
+	"products": {Endpoint: "products", Label: "produk", New: func() any { return &model.CreateProductInput{} }},
+}
+
+// FormRequest isi form yang dikirim client ke /webhook/form
+type FormRequest struct {
+	Form        string         `json:"form"`
+	Values      map[string]any `json:"values"`
+	BearerToken string         `json:"bearer_token"`
+	Slug        string         `json:"slug"`
+	SessionID   string         `json:"session_id"`
+}
+
+// ProcessForm memproses isi form input, hasilnya preview aksi atau form yang sama jika masih ada field tidak valid
+func (bot *ChatBot) ProcessForm(ctx context.Context, req FormRequest) *ZahirResponse {
+	p := auth.FromContext(ctx)
+	if !p.Can(auth.Write) {
+		return forbidden(p, "menambah data")
+	}
+	if _, ok := inputSchemas[req.Form]; !ok {
+		return &ZahirResponse{Status: "error", Message: fmt.Sprintf("Form %q tidak dikenal", req.Form)}
+	}
+	return bot.previewInput(bot.sessions.Get(sessionKey(p, req.SessionID)), req.Form, req.Values)
+}
+
+// previewInput memvalidasi payload create lalu menyimpannya sebagai aksi yang menunggu konfirmasi.
+// Jika ada field kosong atau tidak valid, dikembalikan form berisi nilai yang sudah diisi user
+func (bot *ChatBot) previewInput(sess *session.Session, id string, values map[string]any) *ZahirResponse {
+	schema := inputSchemas[id]
+	input := schema.New()
+	if errs := validation.Decode(values, input); len(errs) > 0 {
+		return inputForm(id, values, errs, fmt.Sprintf("Data %s belum lengkap atau tidak valid", schema.Label))
+	}
+
+	payload := toMap(input)
+	return bot.previewAction(sess, &action.Action{
+		Method:   http.MethodPost,
+		Endpoint: schema.Endpoint,
+		Summary:  fmt.Sprintf("Tambah %s %v", schema.Label, payload["name"]),
+		Payload:  input,
+		Diff:     action.Diff(nil, payload),
+		Form:     id,
+	}, nil)
+}
+
+// inputForm jawaban berupa form input yang dibuat dari schema payload, error ditampilkan per field
+func inputForm(id string, values map[string]any, errs validation.Errors, title string) *ZahirResponse {
+	byField := map[string]string{}
+	for _, e := range errs {
+		if e.Field != "" {
+			byField[e.Field] = e.Message
+		}
+	}
+	blocks := []answer.Block{
+		{Type: answer.TypeText, Text: fmt.Sprintf("%s: %s", title, errs.Error())},
+		{Type: answer.TypeForm, Form: answer.FormFor(id, inputSchemas[id].New(), values, byField)},
+	}
+	return &ZahirResponse{
+		Status:      "OK",
+		Message:     blocks[0].Text,
+		Blocks:      blocks,
+		FieldErrors: errs,
+	}
+}
+
+// toMap mengubah struct payload menjadi map sesuai tag json
+func toMap(v any) map[string]any {
+	m := map[string]any{}
+	b, err := json.Marshal(v)
+	if err == nil {
+		json.Unmarshal(b, &m)
+	}
+	return m
+}
+
+// formHandler menerima isi form input sebagai JSON
+func formHandler(bot *ChatBot) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req FormRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		p, err := bot.authenticate(r, req.BearerToken, req.Slug)
+		if err != nil {
+			writeAuthError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(bot.ProcessForm(auth.NewContext(r.Context(), p), req))
+	}
+}
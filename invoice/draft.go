@@ -0,0 +1,235 @@
+// Package invoice menyusun draft faktur dari permintaan chat: nama kontak dan produk
+// diubah menjadi ID Zahir, total dihitung di server, lalu draft ditampilkan ke user
+// untuk dikonfirmasi sebelum dikirim ke Zahir API.
+package invoice
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/MaulanaR/zai/answer"
+	"github.com/MaulanaR/zai/resolve"
+	"github.com/MaulanaR/zai/zahir"
+)
+
+// Kind jenis faktur beserta endpoint dan peran kontaknya
+type Kind struct {
+	Endpoint   string `json:"endpoint"`
+	Party      string `json:"party"` // customer atau supplier
+	Title      string `json:"title"`
+	PriceField string `json:"price_field"` // field harga default dari produk
+	PastOnly   bool   `json:"past_only"`   // tanggal tidak boleh melewati hari ini
+}
+
+var (
+	// Sales faktur penjualan, harga default harga jual produk
+	Sales = Kind{Endpoint: "sales_invoices", Party: "customer", Title: "Faktur Penjualan", PriceField: "unit_price"}
+	// Purchase faktur pembelian, harga default HPP produk
+	Purchase = Kind{Endpoint: "purchases_invoices", Party: "supplier", Title: "Faktur Pembelian", PriceField: "unit_cogs", PastOnly: true}
+)
+
+// Input permintaan faktur dari tool call, produk masih berupa kode atau nama
+type Input struct {
+	Party       string
+	Date        string
+	Description string
+	TaxRate     float64
+	Lines       []LineInput
+}
+
+// LineInput satu baris barang, UnitPrice nil berarti memakai harga produk
+type LineInput struct {
+	Product   string
+	Quantity  float64
+	UnitPrice *float64
+	Discount  float64
+}
+
+// Line baris barang yang produknya sudah ditemukan
+type Line struct {
+	ProductID   string  `json:"product_id"`
+	ProductCode string  `json:"product_code"`
+	ProductName string  `json:"product_name"`
+	Quantity    float64 `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	Discount    float64 `json:"discount"`
+	Amount      float64 `json:"amount"`
+}
+
+// Draft faktur yang siap dikonfirmasi
+type Draft struct {
+	Kind              Kind    `json:"kind"`
+	PartyID           string  `json:"party_id"`
+	PartyName         string  `json:"party_name"`
+	Date              string  `json:"date"`
+	Description       string  `json:"description,omitempty"`
+	TaxRate           float64 `json:"tax_rate"`
+	Lines             []Line  `json:"line_items"`
+	Subtotal          float64 `json:"subtotal"`
+	TotalDiscount     float64 `json:"total_discount"`
+	SubtotalBeforeTax float64 `json:"subtotal_before_tax"`
+	TotalTax          float64 `json:"total_tax"`
+	TotalAmount       float64 `json:"total_amount"`
+}
+
+// InputFromParams membaca params hasil tools.ParseArgs, partyKey misal "customer_name"
+func InputFromParams(params map[string]any, partyKey string) Input {
+	in := Input{}
+	in.Party, _ = params[partyKey].(string)
+	in.Date, _ = params["date"].(string)
+	in.Description, _ = params["description"].(string)
+	in.TaxRate, _ = params["tax_rate"].(float64)
+
+	items, _ := params["line_items"].([]map[string]any)
+	for _, it := range items {
+		l := LineInput{}
+		l.Product, _ = it["product"].(string)
+		l.Quantity, _ = it["quantity"].(float64)
+		l.Discount, _ = it["discount"].(float64)
+		if price, ok := it["unit_price"].(float64); ok {
+			l.UnitPrice = &price
+		}
+		in.Lines = append(in.Lines, l)
+	}
+	return in
+}
+
+// Build mencari kontak dan produk lewat resolver lalu menghitung total draft, today dipakai
+// untuk tanggal default dan batas tanggal faktur pembelian (zona waktu tenant)
+func Build(ctx context.Context, r *resolve.Resolver, c *zahir.Client, kind Kind, in Input, today time.Time) (*Draft, error) {
+	if len(in.Lines) == 0 {
+		return nil, fmt.Errorf("line_items wajib diisi")
+	}
+	if in.TaxRate < 0 || in.TaxRate > 100 {
+		return nil, fmt.Errorf("tax_rate harus antara 0 dan 100")
+	}
+	if in.Date == "" {
+		in.Date = today.Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", in.Date); err != nil {
+		return nil, fmt.Errorf("tanggal %q harus berformat YYYY-MM-DD", in.Date)
+	}
+	if kind.PastOnly && in.Date > today.Format("2006-01-02") {
+		return nil, fmt.Errorf("tanggal %s tidak boleh melewati hari ini", in.Date)
+	}
+
+	party, err := r.One(ctx, c, resolve.Kind(kind.Party), in.Party)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Draft{
+		Kind:        kind,
+		PartyID:     party.ID,
+		PartyName:   party.Name,
+		Date:        in.Date,
+		Description: in.Description,
+		TaxRate:     in.TaxRate,
+	}
+	for i, l := range in.Lines {
+		if l.Quantity <= 0 {
+			return nil, fmt.Errorf("line_items[%d]: quantity harus lebih dari 0", i)
+		}
+		p, err := r.One(ctx, c, resolve.Product, l.Product)
+		if err != nil {
+			return nil, err
+		}
+		price, _ := p.Record[kind.PriceField].(float64)
+		if l.UnitPrice != nil {
+			price = *l.UnitPrice
+		}
+		if price < 0 || l.Discount < 0 || l.Discount > l.Quantity*price {
+			return nil, fmt.Errorf("line_items[%d]: harga atau diskon tidak valid", i)
+		}
+		d.Lines = append(d.Lines, Line{
+			ProductID:   p.ID,
+			ProductCode: p.Code,
+			ProductName: p.Name,
+			Quantity:    l.Quantity,
+			UnitPrice:   price,
+			Discount:    l.Discount,
+		})
+	}
+	d.Compute()
+	return d, nil
+}
+
+// Compute menghitung ulang jumlah per baris, subtotal, diskon, pajak dan total
+func (d *Draft) Compute() {
+	d.Subtotal, d.TotalDiscount = 0, 0
+	for i := range d.Lines {
+		l := &d.Lines[i]
+		gross := round(l.Quantity * l.UnitPrice)
+		l.Amount = round(gross - l.Discount)
+		d.Subtotal += gross
+		d.TotalDiscount += l.Discount
+	}
+	d.Subtotal = round(d.Subtotal)
+	d.TotalDiscount = round(d.TotalDiscount)
+	d.SubtotalBeforeTax = round(d.Subtotal - d.TotalDiscount)
+	d.TotalTax = round(d.SubtotalBeforeTax * d.TaxRate / 100)
+	d.TotalAmount = round(d.SubtotalBeforeTax + d.TotalTax)
+}
+
+// Body request POST ke Zahir API
+func (d *Draft) Body() map[string]any {
+	items := []map[string]any{}
+	for _, l := range d.Lines {
+		items = append(items, map[string]any{
+			"product":    map[string]any{"id": l.ProductID},
+			"quantity":   l.Quantity,
+			"unit_price": l.UnitPrice,
+			"discount":   map[string]any{"amount": l.Discount},
+		})
+	}
+	body := map[string]any{
+		"date":                d.Date,
+		d.Kind.Party:          map[string]any{"id": d.PartyID},
+		"line_items":          items,
+		"subtotal":            d.Subtotal,
+		"total_discount":      d.TotalDiscount,
+		"subtotal_before_tax": d.SubtotalBeforeTax,
+		"total_tax":           d.TotalTax,
+		"total_amount":        d.TotalAmount,
+	}
+	if d.Description != "" {
+		body["description"] = d.Description
+	}
+	return body
+}
+
+// Blocks tampilan draft untuk dikonfirmasi user
+func (d *Draft) Blocks() []answer.Block {
+	party := "Customer"
+	if d.Kind.Party == "supplier" {
+		party = "Supplier"
+	}
+	lines := [][]any{}
+	for _, l := range d.Lines {
+		lines = append(lines, []any{l.ProductCode, l.ProductName, l.Quantity, l.UnitPrice, l.Discount, l.Amount})
+	}
+	return []answer.Block{
+		{Type: answer.TypeText, Text: fmt.Sprintf("Draft %s\n%s: %s\nTanggal: %s", d.Kind.Title, party, d.PartyName, d.Date)},
+		{Type: answer.TypeTable, Title: "Barang", Table: &answer.Table{
+			Columns: []string{"Kode", "Nama", "Qty", "Harga", "Diskon", "Jumlah"},
+			Types:   []string{answer.ColText, answer.ColText, answer.ColNumber, answer.ColMoney, answer.ColMoney, answer.ColMoney},
+			Rows:    lines,
+		}},
+		{Type: answer.TypeTable, Title: "Total", Table: &answer.Table{
+			Columns: []string{"Keterangan", "Nilai"},
+			Types:   []string{answer.ColText, answer.ColMoney},
+			Rows: [][]any{
+				{"Subtotal", d.Subtotal},
+				{"Diskon", d.TotalDiscount},
+				{fmt.Sprintf("Pajak (%g%%)", d.TaxRate), d.TotalTax},
+				{"Total", d.TotalAmount},
+			},
+		}},
+	}
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
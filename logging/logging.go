@@ -0,0 +1,115 @@
+// Package logging menyiapkan logger log/slog dengan level dan lapisan redaksi: bearer token, API key
+// dan field PII (NPWP, NIK) disamarkan sebelum apa pun ditulis, termasuk di dalam prompt yang di-dump.
+// Request ID di context ikut ditulis supaya log satu request bisa ditelusuri dari webhook sampai Zahir.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats format log yang didukung
+var Formats = []string{"json", "text"}
+
+// New membuat logger JSON (atau teks untuk format "text") yang hanya menulis level >= level.
+// Semua pesan dan atribut melewati r, request_id dari context ikut ditulis
+func New(w io.Writer, format string, level slog.Level, r *Redactor) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	var next slog.Handler = slog.NewJSONHandler(w, opts)
+	if format == "text" {
+		next = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(&handler{next: next, r: r})
+}
+
+type requestIDKey struct{}
+
+// NewRequestID ID acak untuk satu request
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID menyimpan request ID di context, semua log dengan context ini menulis request_id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID request ID dari context, kosong jika tidak ada
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ParseLevel membaca level debug, info, warn atau error
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("level log %q tidak dikenal, gunakan debug, info, warn atau error", s)
+	}
+	return level, nil
+}
+
+// handler meneruskan record ke next setelah pesan dan atributnya disamarkan
+type handler struct {
+	next slog.Handler
+	r    *Redactor
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, rec slog.Record) error {
+	out := slog.NewRecord(rec.Time, rec.Level, h.r.String(rec.Message), rec.PC)
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			out.AddAttrs(slog.String("request_id", id))
+		}
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.attr(a)
+	}
+	return &handler{next: h.next.WithAttrs(redacted), r: h.r}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), r: h.r}
+}
+
+// attr menyamarkan satu atribut, group diproses per anggota
+func (h *handler) attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	if h.r.Key(a.Key) {
+		return slog.String(a.Key, Masked)
+	}
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.r.String(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]any, len(group))
+		for i, g := range group {
+			attrs[i] = h.attr(g)
+		}
+		return slog.Group(a.Key, attrs...)
+	case slog.KindAny:
+		return slog.Any(a.Key, h.r.Value(v.Any()))
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,139 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Masked pengganti nilai rahasia di log
+const Masked = "[REDACTED]"
+
+// DefaultFields key yang nilainya selalu disamarkan: kredensial dan nomor identitas kontak
+var DefaultFields = []string{
+	"authorization", "bearer_token", "access_token", "refresh_token", "token", "api_key", "x-api-key",
+	"password", "secret", "cookie", "set-cookie", "login_key",
+	"tax_id_number", "national_id_number",
+}
+
+var (
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	// format API key provider LLM (OpenAI, Anthropic, Groq)
+	apiKeyPattern = regexp.MustCompile(`\b(?:sk-ant-|sk-|gsk_)[A-Za-z0-9\-_]{16,}`)
+)
+
+// Redactor menyamarkan token, API key dan field PII, baik sebagai key atribut log
+// maupun di dalam teks (prompt, JSON data Zahir, query string)
+type Redactor struct {
+	keys    map[string]bool
+	field   *regexp.Regexp
+	secrets []string
+}
+
+// NewRedactor membuat redactor untuk DefaultFields ditambah fields. Nilai di secrets (misal API_KEY dari env)
+// disamarkan di mana pun muncul, nilai yang terlalu pendek diabaikan supaya tidak menyamarkan kata biasa
+func NewRedactor(fields []string, secrets ...string) *Redactor {
+	r := &Redactor{keys: map[string]bool{}}
+	patterns := []string{}
+	for _, f := range append(append([]string{}, DefaultFields...), fields...) {
+		f = strings.TrimSpace(f)
+		if f == "" || r.keys[normalize(f)] {
+			continue
+		}
+		r.keys[normalize(f)] = true
+		// tax_id_number juga cocok dengan TaxIDNumber dan tax-id-number
+		parts := strings.FieldsFunc(f, func(c rune) bool { return c == '_' || c == '-' })
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		patterns = append(patterns, strings.Join(parts, `[_-]?`))
+	}
+	// nama panjang dulu supaya access_token tidak terpotong menjadi token
+	sort.Slice(patterns, func(i, j int) bool { return len(patterns[i]) > len(patterns[j]) })
+	// key=value, "key": "value", dan JSON yang sudah di-escape di dalam string (\"key\":\"value\")
+	r.field = regexp.MustCompile(`(?i)((?:\\?")?\b(?:` + strings.Join(patterns, "|") + `)(?:\\?")?\s*[:=]\s*)(\\"[^"\\]*\\"|"(?:[^"\\]|\\.)*"|[^\s,;&{}\[\]"\\]+)`)
+
+	for _, s := range secrets {
+		if len(s) >= 8 {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	return r
+}
+
+// Key true jika nilai atribut dengan key ini harus disamarkan
+func (r *Redactor) Key(key string) bool {
+	return r.keys[normalize(key)]
+}
+
+// String menyamarkan bearer token, API key, secret dan nilai field sensitif di dalam teks
+func (r *Redactor) String(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Masked)
+	}
+	s = bearerPattern.ReplaceAllString(s, "${1}"+Masked)
+	s = apiKeyPattern.ReplaceAllString(s, Masked)
+	return r.field.ReplaceAllStringFunc(s, func(m string) string {
+		sub := r.field.FindStringSubmatch(m)
+		switch {
+		case strings.HasPrefix(sub[2], `\"`):
+			return sub[1] + `\"` + Masked + `\"`
+		case strings.HasPrefix(sub[2], `"`):
+			return sub[1] + `"` + Masked + `"`
+		}
+		return sub[1] + Masked
+	})
+}
+
+// Value menyamarkan nilai sembarang (struct, map, slice) lewat bentuk JSON-nya, field sensitif diganti
+// Masked dan setiap string disaring dengan String
+func (r *Redactor) Value(v any) any {
+	switch v := v.(type) {
+	case nil, bool, int, int64, float64:
+		return v
+	case string:
+		return r.String(v)
+	case error:
+		return r.String(v.Error())
+	case fmt.Stringer:
+		return r.String(v.String())
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return r.String(fmt.Sprint(v))
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return r.String(string(b))
+	}
+	return r.walk(generic)
+}
+
+func (r *Redactor) walk(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if r.Key(k) && child != nil && child != "" {
+				v[k] = Masked
+				continue
+			}
+			v[k] = r.walk(child)
+		}
+		return v
+	case []any:
+		for i, child := range v {
+			v[i] = r.walk(child)
+		}
+		return v
+	case string:
+		return r.String(v)
+	}
+	return v
+}
+
+// normalize TaxIDNumber, tax_id_number dan tax-id-number dianggap key yang sama
+func normalize(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(key))
+}
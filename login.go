@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MaulanaR/zai/auth"
+)
+
+// LoginCookie nama cookie HttpOnly berisi ID session login
+const LoginCookie = "zai_session"
+
+// LoginRequest body POST /auth/login
+type LoginRequest struct {
+	BearerToken string `json:"bearer_token"`
+	Slug        string `json:"slug"`
+}
+
+// LoginResult identitas pemanggil beserta masa berlaku session login
+type LoginResult struct {
+	*auth.Principal
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// newLogins membuat session login sesuai LOGIN_STORE. Tanpa LOGIN_KEY, store memory memakai kunci acak
+// sementara; store file dan redis wajib memakai LOGIN_KEY supaya session tetap terbaca setelah restart
+func newLogins() (*auth.Logins, error) {
+	var store auth.Store
+	switch LoginStore {
+	case "memory":
+		store = auth.NewMemoryStore()
+	case "file":
+		fs, err := auth.NewFileStore(LoginStorePath)
+		if err != nil {
+			return nil, err
+		}
+		store = fs
+	case "redis":
+		rs, err := auth.NewRedisStore(RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("REDIS_URL: %w", err)
+		}
+		store = rs
+	default:
+		return nil, fmt.Errorf("LOGIN_STORE %q tidak dikenal, gunakan memory, file atau redis", LoginStore)
+	}
+
+	if LoginKey == "" {
+		if LoginStore != "memory" {
+			return nil, fmt.Errorf("LOGIN_KEY wajib diisi untuk LOGIN_STORE %s", LoginStore)
+		}
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		return auth.NewLogins(store, key, LoginTTL)
+	}
+	key, err := auth.ParseKey(LoginKey)
+	if err != nil {
+		return nil, fmt.Errorf("LOGIN_KEY: %w", err)
+	}
+	return auth.NewLogins(store, key, LoginTTL)
+}
+
+// loginHandler POST /auth/login memverifikasi token Zahir sekali, lalu memberi cookie session HttpOnly.
+// Request berikutnya cukup membawa cookie tanpa bearer_token
+func loginHandler(bot *ChatBot) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req LoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Slug == "" {
+			req.Slug = Slug
+		}
+		p, err := bot.verifier.Verify(r.Context(), req.BearerToken, req.Slug)
+		if err != nil {
+			writeAuthError(w, err)
+			return
+		}
+		id, login, err := bot.logins.Create(r.Context(), p.BearerToken, p.Slug)
+		if err != nil {
+			writeAuthError(w, fmt.Errorf("gagal menyimpan session login: %w", err))
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     LoginCookie,
+			Value:    id,
+			Path:     "/",
+			Expires:  login.ExpiresAt,
+			HttpOnly: true,
+			Secure:   secureRequest(r),
+			SameSite: http.SameSiteLaxMode,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ZahirResponse{Status: "OK", Message: "Login berhasil", Data: LoginResult{Principal: p, ExpiresAt: &login.ExpiresAt}})
+	}
+}
+
+// logoutHandler POST /auth/logout menghapus session login dan cookie-nya
+func logoutHandler(bot *ChatBot) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if c, err := r.Cookie(LoginCookie); err == nil {
+			if err := bot.logins.Delete(r.Context(), c.Value); err != nil {
+				writeAuthError(w, fmt.Errorf("gagal menghapus session login: %w", err))
+				return
+			}
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     LoginCookie,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   secureRequest(r),
+			SameSite: http.SameSiteLaxMode,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ZahirResponse{Status: "OK", Message: "Logout berhasil"})
+	}
+}
+
+// meHandler GET /auth/me identitas dan role pemanggil, 401 jika belum login
+func meHandler(bot *ChatBot) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		p, err := bot.authenticate(r, "", "")
+		if err != nil {
+			writeAuthError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ZahirResponse{Status: "OK", Data: LoginResult{Principal: p}})
+	}
+}
+
+// secureRequest true jika request lewat HTTPS, langsung atau di belakang reverse proxy
+func secureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
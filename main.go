@@ -1,25 +1,38 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
+	"log/slog"
 	"net/http"
-	"net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
-
-	"github.com/MaulanaR/zai/model"
-	"github.com/MaulanaR/zai/prompt"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/MaulanaR/zai/action"
+	"github.com/MaulanaR/zai/aggregate"
+	"github.com/MaulanaR/zai/ai"
+	"github.com/MaulanaR/zai/answer"
+	"github.com/MaulanaR/zai/auth"
+	"github.com/MaulanaR/zai/invoice"
+	"github.com/MaulanaR/zai/logging"
+	"github.com/MaulanaR/zai/resolve"
+	"github.com/MaulanaR/zai/session"
+	"github.com/MaulanaR/zai/tenant"
+	"github.com/MaulanaR/zai/validation"
+	"github.com/MaulanaR/zai/zahir"
 	"github.com/joho/godotenv"
-	"grest.dev/grest"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Konfigurasi
 const (
-	BaseAPIURL = "https://go.zahironline.com/api/v2"
+	DefaultBaseAPIURL = "https://go.zahironline.com/api/v2"
 )
 
 var (
@@ -28,12 +41,51 @@ var (
 	APIKey        string
 	APIUrl        string
 	Port          string
+	BaseAPIURL    = DefaultBaseAPIURL
 	ModelAI       string
-	CacheChat     CacheEntry
-	CacheData     CacheEntry
 	VisionAPIKey  string
 	VisionAPIUrl  string
 	VisionModelAI string
+
+	LLMProvider    string
+	VisionProvider string
+
+	SessionTTL      = 30 * time.Minute
+	SessionMaxTurns = 10
+
+	PendingActionTTL = 10 * time.Minute
+	EntityCacheTTL   = 5 * time.Minute
+
+	AgentMaxSteps    = 5
+	AgentTokenBudget = 20000
+
+	// pengaturan default tenant, bisa diganti per slug lewat /settings
+	Timezone       = "Asia/Jakarta"
+	Currency       = "IDR"
+	Language       = "id"
+	TenantSettings string // file JSON pengaturan per slug, kosong berarti hanya di memory
+
+	// autentikasi: token Zahir pemanggil diverifikasi, BEARER_TOKEN/SLUG env hanya dipakai jika diizinkan
+	AllowEnvCredentials bool
+	EnvCredentialsRole  = auth.Viewer
+	AuthDefaultRole     = auth.Editor
+	AuthRoles           string // file JSON role per slug dan user
+	AuthCacheTTL        = 5 * time.Minute
+
+	// session login /auth/login: token disimpan terenkripsi di memory, file atau redis
+	LoginStore     = "memory"
+	LoginStorePath = "logins"
+	LoginKey       string // kunci AES hex/base64, wajib untuk store file dan redis
+	LoginTTL       = 12 * time.Hour
+	RedisURL       string
+
+	// log level debug menampilkan request ke Zahir dan dump prompt/response AI
+	LogLevel     = slog.LevelInfo
+	LogFormat    = "json" // json atau text
+	RedactFields []string // field PII tambahan yang disamarkan di log, misal phone,email
+
+	// token bearer untuk scrape /metrics, kosong berarti /metrics terbuka
+	MetricsToken string
 )
 
 func Init() {
@@ -49,19 +101,153 @@ func Init() {
 	VisionAPIKey = os.Getenv("VISION_API_KEY")
 	VisionAPIUrl = os.Getenv("VISION_API_URL")
 	VisionModelAI = os.Getenv("VISION_MODEL_AI")
+
+	LLMProvider = os.Getenv("LLM_PROVIDER")
+	VisionProvider = os.Getenv("VISION_PROVIDER")
+	if VisionProvider == "" {
+		VisionProvider = LLMProvider
+	}
 	Port = os.Getenv("PORT")
+	if v := os.Getenv("ZAHIR_BASE_URL"); v != "" {
+		BaseAPIURL = v
+	}
+
+	if v := os.Getenv("SESSION_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid SESSION_TTL: %v", err)
+		}
+		SessionTTL = ttl
+	}
+	if v := os.Getenv("PENDING_ACTION_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid PENDING_ACTION_TTL: %v", err)
+		}
+		PendingActionTTL = ttl
+	}
+	if v := os.Getenv("ENTITY_CACHE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid ENTITY_CACHE_TTL: %v", err)
+		}
+		EntityCacheTTL = ttl
+	}
+	if v := os.Getenv("SESSION_MAX_TURNS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("Invalid SESSION_MAX_TURNS: %v", err)
+		}
+		SessionMaxTurns = n
+	}
+	if v := os.Getenv("AGENT_MAX_STEPS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("Invalid AGENT_MAX_STEPS: %v", err)
+		}
+		AgentMaxSteps = n
+	}
+	if v := os.Getenv("AGENT_TOKEN_BUDGET"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("Invalid AGENT_TOKEN_BUDGET: %v", err)
+		}
+		AgentTokenBudget = n
+	}
+	if v := os.Getenv("TIMEZONE"); v != "" {
+		Timezone = v
+	}
+	if v := os.Getenv("CURRENCY"); v != "" {
+		Currency = v
+	}
+	if v := os.Getenv("LANGUAGE"); v != "" {
+		Language = v
+	}
+	TenantSettings = os.Getenv("TENANT_SETTINGS")
+
+	if v := os.Getenv("ALLOW_ENV_CREDENTIALS"); v != "" {
+		allow, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Fatalf("Invalid ALLOW_ENV_CREDENTIALS: %v", err)
+		}
+		AllowEnvCredentials = allow
+	}
+	if v := os.Getenv("ENV_CREDENTIALS_ROLE"); v != "" {
+		role, err := auth.ParseRole(v)
+		if err != nil {
+			log.Fatalf("Invalid ENV_CREDENTIALS_ROLE: %v", err)
+		}
+		EnvCredentialsRole = role
+	}
+	if v := os.Getenv("AUTH_DEFAULT_ROLE"); v != "" {
+		role, err := auth.ParseRole(v)
+		if err != nil {
+			log.Fatalf("Invalid AUTH_DEFAULT_ROLE: %v", err)
+		}
+		AuthDefaultRole = role
+	}
+	if v := os.Getenv("AUTH_CACHE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid AUTH_CACHE_TTL: %v", err)
+		}
+		AuthCacheTTL = ttl
+	}
+	AuthRoles = os.Getenv("AUTH_ROLES")
+
+	if v := os.Getenv("LOGIN_STORE"); v != "" {
+		LoginStore = v
+	}
+	if v := os.Getenv("LOGIN_STORE_PATH"); v != "" {
+		LoginStorePath = v
+	}
+	if v := os.Getenv("LOGIN_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid LOGIN_TTL: %v", err)
+		}
+		LoginTTL = ttl
+	}
+	LoginKey = os.Getenv("LOGIN_KEY")
+	RedisURL = os.Getenv("REDIS_URL")
+
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		level, err := logging.ParseLevel(v)
+		if err != nil {
+			log.Fatalf("Invalid LOG_LEVEL: %v", err)
+		}
+		LogLevel = level
+	}
+	if v := os.Getenv("LOG_FORMAT"); v != "" {
+		if v != "json" && v != "text" {
+			log.Fatalf("Invalid LOG_FORMAT: %q, use one of %v", v, logging.Formats)
+		}
+		LogFormat = v
+	}
+	if v := os.Getenv("LOG_REDACT_FIELDS"); v != "" {
+		RedactFields = strings.Split(v, ",")
+	}
+	MetricsToken = os.Getenv("METRICS_TOKEN")
 }
 
-// CacheEntry menyimpan data history chat
-type CacheEntry struct {
-	Data string
+// defaultSettings pengaturan tenant dari env, dipakai untuk slug yang belum punya pengaturan sendiri
+func defaultSettings() tenant.Settings {
+	s := tenant.Defaults(Language)
+	s.Timezone, s.Currency = Timezone, Currency
+	return s
 }
 
 // ChatBot struktur untuk menyimpan konfigurasi chatbot
 type ChatBot struct {
-	client    *http.Client
-	cacheChat *CacheEntry
-	cacheData *CacheEntry
+	client   *http.Client
+	llm      ai.LLMClient
+	vision   ai.LLMClient
+	sessions *session.Store
+	actions  *action.Store
+	entities *resolve.Resolver
+	settings *tenant.Store
+	verifier *auth.Verifier
+	logins   *auth.Logins
 }
 
 // Struktur lainnya tetap sama
@@ -70,141 +256,131 @@ type WebhookRequest struct {
 	Image       string `json:"image"`
 	BearerToken string `json:"bearer_token"`
 	Slug        string `json:"slug"`
+	SessionID   string `json:"session_id"`
+
+	// konfirmasi/pembatalan aksi tulis berdasarkan pending_action_id
+	Confirm string `json:"confirm,omitempty"`
+	Cancel  string `json:"cancel,omitempty"`
 }
 
 type ZahirResponse struct {
-	Status  string      `json:"status"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"results"`
-	Error   interface{} `json:"error"`
+	Status  string         `json:"status"`
+	Message string         `json:"message"`
+	Data    interface{}    `json:"results"`
+	Error   interface{}    `json:"error"`
+	Blocks  []answer.Block `json:"blocks,omitempty"`
+	Trace   []AgentStep    `json:"trace,omitempty"`
+
+	// diisi jika jawaban berupa preview aksi tulis yang menunggu konfirmasi
+	PendingAction *action.Action `json:"pending_action,omitempty"`
+	// diisi jika input ditolak karena field kosong atau tidak valid
+	FieldErrors validation.Errors `json:"field_errors,omitempty"`
+	// diisi jika bot bertanya balik, jawaban user berikutnya melanjutkan permintaan awal
+	Clarify *Clarify `json:"clarify,omitempty"`
 }
 
 type APIDecision struct {
 	Input    bool           `json:"input"`
+	Tool     string         `json:"tool"`
+	Method   string         `json:"method"`
 	Endpoint string         `json:"endpoint"`
 	Type     string         `json:"type"`
 	Params   map[string]any `json:"params"`
-}
-
-func NewChatBot() *ChatBot {
-	return &ChatBot{
-		client:    &http.Client{},
-		cacheChat: &CacheChat,
-		cacheData: &CacheData,
-	}
-}
-
-func (bot *ChatBot) getAPIDecisionEndpointCategory(message string) (*APIDecision, error) {
-	claudeResp, err := bot.askClaudeJson(message, prompt.SystemMSG())
-	if err != nil {
-		return nil, err
-	}
+	Target   string         `json:"target,omitempty"` // kode/nama data yang diubah atau dihapus
 
-	// Remove ```json ``` from the response if present
-	claudeResp = strings.TrimPrefix(claudeResp, "```json")
-	claudeResp = strings.TrimSuffix(claudeResp, "```")
+	// params berisi nama kontak/produk yang dicocokkan dengan resolver sebelum query
+	Entities map[string]resolve.Kind `json:"entities,omitempty"`
 
-	var decision APIDecision
-	if err := json.Unmarshal([]byte(claudeResp), &decision); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %v", err)
-	}
+	// diisi jika data tidak dikirim mentah ke model tapi diagregasi lokal
+	Aggregate *aggregate.Query `json:"aggregate,omitempty"`
 
-	return &decision, nil
+	// diisi jika agent perlu bertanya ke user sebelum memanggil Zahir
+	Clarify *Clarify `json:"clarify,omitempty"`
 }
 
-// getAPIDecision menggunakan Claude untuk menentukan endpoint yang sesuai
-func (bot *ChatBot) getAPIDecision(message string, systemPrompt string) (*APIDecision, error) {
-	claudeResp, err := bot.askClaudeJson(message, systemPrompt)
-	if err != nil {
-		return nil, err
-	}
-
-	var decision APIDecision
-	if err := json.Unmarshal([]byte(claudeResp), &decision); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %v", err)
-	}
+// Clarify pertanyaan klarifikasi beserta pilihan jawaban, Slot adalah informasi yang kurang
+// (key param untuk keputusan yang dilanjutkan otomatis)
+type Clarify struct {
+	Question string   `json:"question"`
+	Choices  []string `json:"choices,omitempty"`
+	Slot     string   `json:"slot,omitempty"`
+}
 
-	return &decision, nil
+// NewChatBot membuat chatbot dengan client LLM untuk teks dan vision, pengaturan tenant, role user
+// serta session login
+func NewChatBot(llm, vision ai.LLMClient, settings *tenant.Store, roles auth.Roles, logins *auth.Logins) *ChatBot {
+	bot := &ChatBot{
+		client:   &http.Client{},
+		llm:      llm,
+		vision:   vision,
+		sessions: session.NewStore(SessionTTL, SessionMaxTurns),
+		actions:  action.NewStore(PendingActionTTL),
+		entities: resolve.New(EntityCacheTTL),
+		settings: settings,
+		logins:   logins,
+	}
+	bot.verifier = auth.NewVerifier(bot.introspect, roles, AuthDefaultRole, AuthCacheTTL)
+	return bot
 }
 
-// Add new function for Vision AI
-func (bot *ChatBot) askVisionAI(imageBase64, prompt string) (string, error) {
-	message := []map[string]interface{}{
-		{
-			"role": "user",
-			"content": []map[string]interface{}{
-				{
-					"type": "text",
-					"text": prompt,
-				},
-				{
-					"type": "image_url",
-					"image_url": map[string]string{
-						"url": imageBase64,
-					},
-				},
-			},
+// askVisionAI mengirim gambar ke model vision untuk dianalisa
+func (bot *ChatBot) askVisionAI(ctx context.Context, imageBase64, prompt string) (string, error) {
+	start := time.Now()
+	aiResp, err := bot.vision.Complete(ctx, ai.Request{
+		Model: VisionModelAI,
+		Messages: []ai.Message{
+			{Role: "user", Content: prompt, Images: []string{imageBase64}},
 		},
-	}
-
-	visionReq := map[string]interface{}{
-		"model":      VisionModelAI,
-		"messages":   message,
-		"max_tokens": 3500,
-	}
-
-	jsonData, err := json.Marshal(visionReq)
-	if err != nil {
-		return "", err
-	}
-
-	req, err := http.NewRequest("POST", VisionAPIUrl, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+VisionAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := bot.client.Do(req)
+		MaxTokens: 3500,
+	})
+	observeLLM(VisionModelAI, "vision", start, aiResp)
 	if err != nil {
+		visionRequestsTotal.WithLabelValues("error").Inc()
+		slog.WarnContext(ctx, "llm call", "stage", "vision", "model", VisionModelAI, "duration_ms", time.Since(start).Milliseconds(), "err", err)
 		return "", err
 	}
-	defer resp.Body.Close()
+	slog.InfoContext(ctx, "llm call", "stage", "vision", "model", VisionModelAI, "duration_ms", time.Since(start).Milliseconds(),
+		"prompt_tokens", aiResp.Usage.PromptTokens, "completion_tokens", aiResp.Usage.CompletionTokens, "total_tokens", aiResp.Usage.TotalTokens)
+	visionRequestsTotal.WithLabelValues("ok").Inc()
 
-	var visionResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&visionResp); err != nil {
-		return "", err
-	}
+	return aiResp.Content, nil
+}
 
-	if choices, ok := visionResp["choices"].([]interface{}); ok && len(choices) > 0 {
-		if choice, ok := choices[0].(map[string]interface{}); ok {
-			if message, ok := choice["message"].(map[string]interface{}); ok {
-				if content, ok := message["content"].(string); ok {
-					return content, nil
-				}
-			}
-		}
-	}
+// ProcessMessage memproses pesan user dan mengembalikan jawaban lengkap
+func (bot *ChatBot) ProcessMessage(ctx context.Context, req WebhookRequest) *ZahirResponse {
+	return bot.processMessage(ctx, req, nil)
+}
 
-	return "", fmt.Errorf("invalid response format from Vision AI")
+// ProcessMessageStream sama dengan ProcessMessage, progres dan token jawaban dikirim lewat emit
+func (bot *ChatBot) ProcessMessageStream(ctx context.Context, req WebhookRequest, emit emitter) *ZahirResponse {
+	return bot.processMessage(ctx, req, emit)
 }
 
-// Modify ProcessMessage to accept dynamic BearerToken and Slug
-func (bot *ChatBot) ProcessMessage(req WebhookRequest) *ZahirResponse {
-	// Use dynamic BearerToken and Slug if provided, else fallback to env
-	bearerToken := req.BearerToken
-	if bearerToken == "" {
-		bearerToken = BearerToken
+// processMessage memakai kredensial Zahir milik principal di ctx, bukan bearer_token/slug di body
+func (bot *ChatBot) processMessage(ctx context.Context, req WebhookRequest, emit emitter) (res *ZahirResponse) {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return &ZahirResponse{Status: "error", Message: auth.ErrUnauthenticated.Error()}
 	}
-	slug := req.Slug
-	if slug == "" {
-		slug = Slug
+	bearerToken, slug := p.BearerToken, p.Slug
+	sess := bot.sessions.Get(sessionKey(p, req.SessionID))
+	start := time.Now()
+	defer func() {
+		logResult(ctx, res, start)
+		observeResult(res)
+	}()
+	// angka, uang dan tanggal pada table diformat sesuai pengaturan tenant
+	defer func() { localize(res, bot.settings.Get(slug)) }()
+
+	// konfirmasi atau pembatalan aksi tulis yang menunggu
+	if res := bot.handleDecision(ctx, sess, req, bearerToken, slug); res != nil {
+		return res
 	}
 
 	// If image exists, process with Vision AI first
 	if req.Image != "" {
-		visionResponse, err := bot.askVisionAI(req.Image, `Analisa gambar lalu berikan data apa yang tampil, tentukan berdasarkan aturan ini : 
+		emit.send("progress", progress{Stage: "vision", Message: "Menganalisa gambar"})
+		visionResponse, err := bot.askVisionAI(ctx, req.Image, `Analisa gambar lalu berikan data apa yang tampil, tentukan berdasarkan aturan ini : 
 		<available_fields>
 				<sales_invoices>
 					- customer.name
@@ -275,538 +451,295 @@ func (bot *ChatBot) ProcessMessage(req WebhookRequest) *ZahirResponse {
 		}
 	}
 
-	// Continue with existing logic for processing message
-	endCat, err := bot.getAPIDecisionEndpointCategory(req.Message)
-	if err != nil {
-		return &ZahirResponse{
-			Status:  "error",
-			Message: fmt.Sprintf("Gagal menentukan kebutuhan kategori API: %v", err),
-		}
+	// jawaban atas pertanyaan klarifikasi melanjutkan permintaan sebelumnya
+	message, resume := bot.resumeClarification(sess, req.Message)
+	res = bot.runAgent(ctx, sess, message, bearerToken, slug, emit, resume)
+	// client selalu menerima blocks, pesan biasa menjadi satu block text
+	if res.Status == "OK" && len(res.Blocks) == 0 && res.Message != "" {
+		res.Blocks = answer.Text(res.Message)
 	}
+	return res
+}
 
-	if endCat.Input {
-		if endCat.Type == "kontak" || endCat.Type == "customer" || endCat.Type == "supplier" || endCat.Type == "employee" || endCat.Type == "products" {
-			zRes, err := bot.postToAPI(endCat.Endpoint, endCat.Params, bearerToken, slug)
-			if err != nil {
-				return &ZahirResponse{
-					Status:  "error",
-					Message: fmt.Sprintf("Gagal input via api: %v", err),
-				}
-			}
-
-			// jika errornya ada, maka balikan ke ai
-			if zRes.Error != nil {
-				rs, err := bot.askAI(req.Message, prompt.GenerateForm())
-				zRes.Status = "OK"
-				zRes.Message = rs
-				zRes.Error = nil
-				if err != nil {
-					return &ZahirResponse{
-						Status:  "Error",
-						Message: "Gagal generate form",
-					}
-				}
-			}
-			return &zRes
-		}
-
-		// reset
-		CacheData = CacheEntry{}
-	} else {
-		if endCat.Endpoint != "" && endCat.Endpoint != "null" {
-			// memerlukan data baru
-			apiResp, err := bot.getDataFromAPIWithAuth(endCat, bearerToken, slug)
-			if err != nil {
-				return &ZahirResponse{
-					Status:  "error",
-					Message: fmt.Sprintf("Gagal mengambil data: %v", err),
-				}
-			}
-			fmt.Println("===== RESPON FROM API =====")
-			fmt.Println(apiResp.Data)
-			fmt.Println("===== END RESPON FROM API =====")
-
-			interpretation, err := bot.interpretAPIResponse(req.Message, apiResp, endCat.Endpoint)
-			if err != nil {
-				return apiResp
-			}
-
-			// add to cache
-			CacheChat = CacheEntry{interpretation}
-
-			return &ZahirResponse{
-				Status:  "OK",
-				Message: interpretation,
-			}
-		} else {
-			interpretation, err := bot.interpretMessage(req.Message)
-			if err != nil {
-				return &ZahirResponse{
-					Status:  "error",
-					Message: fmt.Sprintf("Gagal menginterpretasi pesan: %v", err),
-				}
-			}
-
-			// add to cache
-			CacheChat = CacheEntry{interpretation}
-
-			return &ZahirResponse{
-				Status:  "OK",
-				Message: interpretation,
-			}
-		}
+// logResult menulis ringkasan satu pesan: status, durasi total, jumlah langkah agent dan token
+func logResult(ctx context.Context, res *ZahirResponse, start time.Time) {
+	if res == nil {
+		return
 	}
-
-	return &ZahirResponse{
-		Status:  "error",
-		Message: fmt.Sprintf("Gagal menentukan kebutuhan ANDA: %v", err),
+	tokens := 0
+	for _, st := range res.Trace {
+		tokens += st.Tokens
+	}
+	attrs := []any{"status", res.Status, "duration_ms", time.Since(start).Milliseconds(), "steps", len(res.Trace), "total_tokens", tokens}
+	if res.Status != "OK" {
+		attrs = append(attrs, "message", res.Message)
 	}
+	slog.InfoContext(ctx, "message processed", attrs...)
 }
 
-// Fungsi helper lainnya (getDataFromAPI, askClaude, interpretAPIResponse) tetap sama
-func (bot *ChatBot) getDataFromAPI(decision *APIDecision) (*ZahirResponse, error) {
-	params := url.Values{}
-	for key, value := range decision.Params {
-		params.Add(key, fmt.Sprintf("%v", value))
+// localize memformat table pada jawaban, Message ikut diperbarui jika berasal dari blocks
+func localize(res *ZahirResponse, s tenant.Settings) {
+	if res == nil || len(res.Blocks) == 0 {
+		return
 	}
-
-	urlStr := fmt.Sprintf("%s/%s", BaseAPIURL, strings.TrimSpace(decision.Endpoint))
-	if len(params) > 0 {
-		urlStr = fmt.Sprintf("%s?%s", urlStr, params.Encode())
+	plain := answer.PlainText(res.Blocks)
+	res.Blocks = answer.Localize(res.Blocks, s)
+	if res.Message == plain {
+		res.Message = answer.PlainText(res.Blocks)
 	}
+}
 
-	req, err := http.NewRequest("GET", urlStr, nil)
-	if err != nil {
-		return nil, err
+// handleInput menjalankan input data (POST/PATCH/DELETE) hasil keputusan agent
+func (bot *ChatBot) handleInput(ctx context.Context, sess *session.Session, decision *APIDecision, bearerToken, slug string) *ZahirResponse {
+	if p := auth.FromContext(ctx); !p.Can(auth.Write) {
+		return forbidden(p, "menambah, mengubah atau menghapus data")
 	}
-
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", BearerToken))
-	req.Header.Add("slug", Slug)
-	req.Header.Add("Content-Type", "application/json")
-
-	fmt.Println("========")
-	fmt.Printf("Request URL: %s\n", urlStr)
-	fmt.Printf("Request Headers: %v\n", req.Header)
-	fmt.Printf("Request Body: %s\n", params.Encode())
-	fmt.Println("========")
-
-	resp, err := bot.client.Do(req)
-	if err != nil {
-		return nil, err
+	if decision.Method == http.MethodPatch || decision.Method == http.MethodDelete {
+		return bot.previewChange(ctx, sess, decision, bearerToken, slug)
 	}
-	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var zahirResp ZahirResponse
-	switch decision.Endpoint {
-	case "contacts":
-		r := model.ContactResp{}
-		if err := grest.NewJSON(bodyBytes, true).ToFlat().Unmarshal(&r); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = r.Data
+	switch decision.Type {
 	case "sales_invoices":
-		r := model.SalesInvoicesResp{}
-		if err := grest.NewJSON(bodyBytes, true).ToFlat().Unmarshal(&r); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = r.Data
-	case "products":
-		r := model.ProductResp{}
-		if err := grest.NewJSON(bodyBytes, true).ToFlat().Unmarshal(&r); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = r.Data
+		return bot.draftInvoice(ctx, sess, invoice.Sales, decision, bearerToken, slug)
 	case "purchases_invoices":
-		r := model.PurchaseInvResp{}
-		if err := grest.NewJSON(bodyBytes, true).ToFlat().Unmarshal(&r); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = r.Data
-	case "dashboards/daily_sales":
-		var d interface{}
-		if err := json.Unmarshal(bodyBytes, &d); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = d
-	case "dashboards/balance_sheet_simple":
-		var d interface{}
-		if err := json.Unmarshal(bodyBytes, &d); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = d
-	default:
-		if err := json.Unmarshal(bodyBytes, &zahirResp); err != nil {
-			var d interface{}
-			if err := json.Unmarshal(bodyBytes, &d); err != nil {
-				return nil, err
-			}
-			zahirResp.Data = d
-		}
+		return bot.draftInvoice(ctx, sess, invoice.Purchase, decision, bearerToken, slug)
 	}
 
-	return &zahirResp, nil
-}
-
-// interpretMessage menangani pesan yang tidak memerlukan data baru
-func (bot *ChatBot) interpretMessage(message string) (string, error) {
-	prompt := message
-
-	return bot.askClaudePlain(prompt)
-}
-
-func (bot *ChatBot) askAI(prompt string, systemPromt string) (string, error) {
-	//build message with assistant
-	message := []map[string]string{
-		{
-			"role":    "system",
-			"content": systemPromt,
-		},
+	if _, ok := inputSchemas[decision.Endpoint]; ok {
+		// data tidak langsung dikirim, divalidasi lalu user harus konfirmasi preview terlebih dahulu
+		return bot.previewInput(sess, decision.Endpoint, decision.Params)
 	}
 
-	// cache data
-	if bot.cacheData.Data != "" {
-		message = append(message, map[string]string{
-			"role":    "system",
-			"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(bot.cacheData.Data),
-		})
-	}
-	// cache chat
-	if bot.cacheChat.Data != "" {
-		message = append(message, map[string]string{
-			"role": "assistant",
-			// "name":    "maulana",
-			"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(bot.cacheChat.Data),
-		})
+	// reset
+	sess.ClearData()
+	return &ZahirResponse{
+		Status:  "error",
+		Message: fmt.Sprintf("Gagal menentukan kebutuhan ANDA: input %s belum didukung", decision.Type),
 	}
-	message = append(message, map[string]string{
-		"role":    "user",
-		"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(prompt),
-	})
+}
 
-	claudeReq := map[string]interface{}{
-		// "user":        "maulana",
-		"model":       ModelAI,
-		"temperature": 0,
-		"top_p":       0.01,
-		"max_tokens":  3500,
-		"messages":    message,
-		// "response_format": map[string]string{
-		// 	"type": "json_object",
-		// },
-	}
+// zahirClient membuat client Zahir API dengan kredensial user
+func (bot *ChatBot) zahirClient(bearerToken, slug string) *zahir.Client {
+	return zahir.NewClient(BaseAPIURL, bearerToken, slug, bot.client)
+}
 
-	fmt.Println("==== REQ yang dikirim ke AI ====")
-	fmt.Println(claudeReq)
-	fmt.Println("==== END REQ yang dikirim ke AI ====")
+// batas baris data Zahir yang dikirim ke model jika per_page tidak diisi, dan batas maksimalnya
+const (
+	DefaultRows         = 10
+	DefaultContactsRows = 50
+	MaxRows             = 100
+)
 
-	jsonData, err := json.Marshal(claudeReq)
-	if err != nil {
-		return "", err
-	}
+// fetchData mengambil data dari Zahir API sesuai keputusan agent. Data yang dikirim ke model
+// dibatasi per_page (maksimal MaxRows), agregasi tetap menghitung semua halaman
+func (bot *ChatBot) fetchData(ctx context.Context, decision *APIDecision, bearerToken, slug string) (*ZahirResponse, error) {
+	slog.DebugContext(ctx, "zahir request", "endpoint", decision.Endpoint, "params", decision.Params)
 
-	req, err := http.NewRequest("POST", APIUrl, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
+	f := zahir.FilterFromParams(decision.Params)
+	if decision.Aggregate == nil {
+		f.Limit(rowLimit(decision))
 	}
-
-	req.Header.Set("Authorization", "Bearer "+APIKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := bot.client.Do(req)
+	data, err := bot.zahirClient(bearerToken, slug).List(ctx, decision.Endpoint, f)
 	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var claudeResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&claudeResp); err != nil {
-		return "", err
+		return nil, err
 	}
-
-	fmt.Println("RESP AI JSON ====")
-	fmt.Println("claudeResp", claudeResp)
-
-	if choices, ok := claudeResp["choices"].([]interface{}); ok && len(choices) > 0 {
-		if choice, ok := choices[0].(map[string]interface{}); ok {
-			if message, ok := choice["message"].(map[string]interface{}); ok {
-				if content, ok := message["content"].(interface{}); ok {
-					return fmt.Sprintf("%s", content), nil
-				}
-			}
+	if decision.Aggregate != nil {
+		result, err := aggregateData(data, *decision.Aggregate, bot.settings.Get(slug))
+		if err != nil {
+			return nil, err
 		}
+		return &ZahirResponse{Data: result}, nil
 	}
-
-	return "", fmt.Errorf("invalid response format from Claude")
+	return &ZahirResponse{Data: data}, nil
 }
 
-func (bot *ChatBot) askClaudeJson(prompt string, systemPromt string) (string, error) {
-	//build message with assistant
-	message := []map[string]string{
-		{
-			"role":    "system",
-			"content": systemPromt,
-		},
-	}
-
-	// cache data
-	if bot.cacheData.Data != "" {
-		message = append(message, map[string]string{
-			"role":    "system",
-			"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(bot.cacheData.Data),
-		})
-	}
-	// cache chat
-	if bot.cacheChat.Data != "" {
-		message = append(message, map[string]string{
-			"role": "assistant",
-			// "name":    "maulana",
-			"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(bot.cacheChat.Data),
-		})
-	}
-	message = append(message, map[string]string{
-		"role":    "user",
-		"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(prompt),
-	})
-
-	claudeReq := map[string]interface{}{
-		// "user":        "maulana",
-		"model":       ModelAI,
-		"temperature": 0,
-		"top_p":       0.01,
-		"max_tokens":  3500,
-		"messages":    message,
-		// "response_format": map[string]string{
-		// 	"type": "json_object",
-		// },
-	}
-
-	fmt.Println("==== REQ yang dikirim ke AI ====")
-	fmt.Println(claudeReq)
-	fmt.Println("==== END REQ yang dikirim ke AI ====")
-
-	jsonData, err := json.Marshal(claudeReq)
-	if err != nil {
-		return "", err
-	}
-
-	req, err := http.NewRequest("POST", APIUrl, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+APIKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := bot.client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var claudeResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&claudeResp); err != nil {
-		return "", err
-	}
-
-	fmt.Println("RESP AI JSON ====")
-	fmt.Println("claudeResp", claudeResp)
-
-	if choices, ok := claudeResp["choices"].([]interface{}); ok && len(choices) > 0 {
-		if choice, ok := choices[0].(map[string]interface{}); ok {
-			if message, ok := choice["message"].(map[string]interface{}); ok {
-				if content, ok := message["content"].(string); ok {
-					return content, nil
-				}
-			}
+// rowLimit jumlah baris dari per_page, default DefaultRows (DefaultContactsRows untuk kontak), paling banyak MaxRows
+func rowLimit(decision *APIDecision) int {
+	n, err := strconv.Atoi(fmt.Sprint(decision.Params["per_page"]))
+	if err != nil || n <= 0 {
+		n = DefaultRows
+		if decision.Endpoint == "contacts" {
+			n = DefaultContactsRows
 		}
 	}
-
-	return "", fmt.Errorf("invalid response format from Claude")
+	return min(n, MaxRows)
 }
 
-func (bot *ChatBot) askClaudePlain(userMsg string) (string, error) {
-	//build message with assistant
-	message := []map[string]string{
-		{
-			"role":    "system",
-			"content": prompt.GenerateResRule(),
-		},
+// aggregateData menghitung agregasi lokal, line_items dipecah per item jika group/field berasal dari item
+func aggregateData(data any, q aggregate.Query, s tenant.Settings) (any, error) {
+	records, err := aggregate.FromModels(data)
+	if err != nil {
+		return nil, err
 	}
-	// cache data
-	if bot.cacheData.Data != "" {
-		message = append(message, map[string]string{
-			"role": "system",
-			// "name":    "maulana",
-			"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(bot.cacheData.Data),
-		})
+	if usesLineItems(q) {
+		records = aggregate.Unnest(records, "line_items")
+		for _, r := range records {
+			qty, _ := r["line_items.quantity"].(float64)
+			price, _ := r["line_items.unit_price"].(float64)
+			discount, _ := r["line_items.discount.amount"].(float64)
+			r["line_items.amount"] = qty*price - discount
+		}
 	}
-	// cache chat
-	if bot.cacheChat.Data != "" {
-		message = append(message, map[string]string{
-			"role": "assistant",
-			// "name":    "maulana",
-			"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(bot.cacheChat.Data),
-		})
+	if !moneyFields[q.Field] || q.Func == aggregate.Count {
+		return aggregate.Run(records, q)
 	}
-	message = append(message, map[string]string{
-		"role":    "user",
-		"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(userMsg),
-	})
+	return aggregateMoney(records, q, s)
+}
 
-	claudeReq := map[string]interface{}{
-		// "user":        "maulana",
-		"model":       ModelAI,
-		"temperature": 0,
-		"top_p":       0.01,
-		"messages":    message,
-		"max_tokens":  3500,
-	}
+// moneyFields field nominal yang nilainya dalam mata uang faktur (currency.name)
+var moneyFields = map[string]bool{
+	"total_amount": true, "total_payment": true, "line_items.amount": true, "line_items.unit_price": true,
+}
 
-	fmt.Println("==== REQ yang dikirim ke AI PLAIN====")
-	fmt.Println(claudeReq)
-	fmt.Println("==== END REQ yang dikirim ke AI PLAIN====")
+// MoneyResult hasil agregasi nominal beserta mata uangnya
+type MoneyResult struct {
+	Currency  string `json:"currency"`
+	Converted bool   `json:"converted,omitempty"` // nilai mata uang asing sudah dikonversi dengan kurs tenant
+	*aggregate.Result
+}
 
-	jsonData, err := json.Marshal(claudeReq)
-	if err != nil {
-		return "", err
+// aggregateMoney tidak menjumlahkan nominal beda mata uang: jika semua kurs tersedia dan konversi
+// aktif, nilai dikonversi ke mata uang tenant, selain itu hasilnya dipisah per mata uang
+func aggregateMoney(records []aggregate.Record, q aggregate.Query, s tenant.Settings) (any, error) {
+	byCurrency := map[string][]aggregate.Record{}
+	currencies := []string{}
+	for _, r := range records {
+		cur, _ := r["currency.name"].(string)
+		if cur == "" {
+			cur = s.Currency
+		}
+		if _, ok := byCurrency[cur]; !ok {
+			currencies = append(currencies, cur)
+		}
+		byCurrency[cur] = append(byCurrency[cur], r)
 	}
+	sort.Strings(currencies)
 
-	req, err := http.NewRequest("POST", APIUrl, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
+	if len(currencies) <= 1 || q.GroupBy == "currency.name" {
+		result, err := aggregate.Run(records, q)
+		if err != nil {
+			return nil, err
+		}
+		cur := s.Currency
+		if len(currencies) == 1 {
+			cur = currencies[0]
+		}
+		return &MoneyResult{Currency: cur, Result: result}, nil
 	}
 
-	req.Header.Set("Authorization", "Bearer "+APIKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := bot.client.Do(req)
-	if err != nil {
-		return "", err
+	convert := s.Convert
+	for _, cur := range currencies {
+		if _, ok := s.Exchange(0, cur); !ok {
+			convert = false
+		}
 	}
-	defer resp.Body.Close()
-
-	var claudeResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&claudeResp); err != nil {
-		return "", err
+	if convert {
+		for _, r := range records {
+			if v, ok := r[q.Field].(float64); ok {
+				cur, _ := r["currency.name"].(string)
+				r[q.Field], _ = s.Exchange(v, cur)
+			}
+		}
+		result, err := aggregate.Run(records, q)
+		if err != nil {
+			return nil, err
+		}
+		return &MoneyResult{Currency: s.Currency, Converted: true, Result: result}, nil
 	}
 
-	if choices, ok := claudeResp["choices"].([]interface{}); ok && len(choices) > 0 {
-		if choice, ok := choices[0].(map[string]interface{}); ok {
-			if message, ok := choice["message"].(map[string]interface{}); ok {
-				if content, ok := message["content"].(string); ok {
-					return content, nil
-				}
-			}
+	results := []*MoneyResult{}
+	for _, cur := range currencies {
+		result, err := aggregate.Run(byCurrency[cur], q)
+		if err != nil {
+			return nil, err
 		}
+		results = append(results, &MoneyResult{Currency: cur, Result: result})
 	}
+	return results, nil
+}
 
-	return "", fmt.Errorf("invalid response format from Claude, detail %v", claudeResp)
+func usesLineItems(q aggregate.Query) bool {
+	return strings.HasPrefix(q.GroupBy, "line_items.") || strings.HasPrefix(q.Field, "line_items.")
 }
 
-func (bot *ChatBot) askClaudeFromAPIRes(userMsg, endpoint, apiData string) (string, error) {
-	//build message with assistant
-	message := []map[string]string{
-		{
-			"role":    "system",
-			"content": prompt.GenerateResRule(),
-		},
+// buildMessages menyusun system prompt, history session dan pesan user
+func (bot *ChatBot) buildMessages(sess *session.Session, systemPrompt, userMsg string) []ai.Message {
+	messages := []ai.Message{
+		{Role: "system", Content: systemPrompt},
 	}
-	// cache data
-	if bot.cacheData.Data != "" {
-		message = append(message, map[string]string{
-			"role":    "system",
-			"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(bot.cacheData.Data),
+	for _, m := range sess.Messages() {
+		messages = append(messages, ai.Message{
+			Role:    m.Role,
+			Content: strings.NewReplacer("\n", " ", "\t", " ").Replace(m.Content),
 		})
 	}
-	// cache chat
-	if bot.cacheChat.Data != "" {
-		message = append(message, map[string]string{
-			"role":    "assistant",
-			"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(bot.cacheChat.Data),
-		})
-	}
-
-	message = append(message, map[string]string{
-		"role":    "user",
-		"content": strings.NewReplacer("\n", " ", "\t", " ").Replace(userMsg),
+	return append(messages, ai.Message{
+		Role:    "user",
+		Content: strings.NewReplacer("\n", " ", "\t", " ").Replace(userMsg),
 	})
+}
 
-	claudeReq := map[string]interface{}{
-		// "user":        "maulana",
-		"model":       ModelAI,
-		"temperature": 0,
-		"top_p":       0.6,
-		"messages":    message,
-		"max_tokens":  3500,
-	}
+// complete meneruskan request ke LLM
+func (bot *ChatBot) complete(ctx context.Context, aiReq ai.Request) (*ai.Response, error) {
+	return bot.completeStream(ctx, aiReq, nil)
+}
 
-	fmt.Println("==== REQ yang dikirim ke AI gen after API ====")
-	fmt.Println(claudeReq)
-	fmt.Println("==== END REQ yang dikirim ke AI gen after API ====")
+// completeStream meneruskan request ke LLM, jika onDelta diisi token dikirim selama streaming
+func (bot *ChatBot) completeStream(ctx context.Context, aiReq ai.Request, onDelta func(string)) (*ai.Response, error) {
+	slog.DebugContext(ctx, "llm request", "request", aiReq)
 
-	jsonData, err := json.Marshal(claudeReq)
+	start := time.Now()
+	aiResp, err := ai.CompleteStream(ctx, bot.llm, aiReq, onDelta)
+	observeLLM(aiReq.Model, "chat", start, aiResp)
 	if err != nil {
-		return "", err
+		slog.WarnContext(ctx, "llm call", "model", aiReq.Model, "duration_ms", time.Since(start).Milliseconds(), "err", err)
+		return nil, err
 	}
-
-	req, err := http.NewRequest("POST", APIUrl, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
+	// model yang memanggil tool sedang memutuskan data apa yang diambil, selain itu menginterpretasi data
+	stage := "interpret"
+	if len(aiResp.ToolCalls) > 0 {
+		stage = "decide"
 	}
+	slog.InfoContext(ctx, "llm call", "stage", stage, "model", aiReq.Model, "duration_ms", time.Since(start).Milliseconds(),
+		"prompt_tokens", aiResp.Usage.PromptTokens, "completion_tokens", aiResp.Usage.CompletionTokens, "total_tokens", aiResp.Usage.TotalTokens,
+		"tool_calls", len(aiResp.ToolCalls), "finish_reason", aiResp.FinishReason)
 
-	req.Header.Set("Authorization", "Bearer "+APIKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := bot.client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
+	slog.DebugContext(ctx, "llm response", "content", aiResp.Content, "tool_calls", aiResp.ToolCalls, "usage", aiResp.Usage)
 
-	var claudeResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&claudeResp); err != nil {
-		return "", err
-	}
+	return aiResp, nil
+}
 
-	fmt.Println("==== RESPONSE SETELAH OLAHAN API ====")
-	fmt.Println(claudeResp)
-	fmt.Println("==== END RESPONSE SETELAH OLAHAN API ====")
-	if choices, ok := claudeResp["choices"].([]interface{}); ok && len(choices) > 0 {
-		if choice, ok := choices[0].(map[string]interface{}); ok {
-			if message, ok := choice["message"].(map[string]interface{}); ok {
-				if content, ok := message["content"].(string); ok {
-					return content, nil
-				}
-			}
+// Main dan webhook handler tetap sama
+func webhookHandler(bot *ChatBot) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
 		}
-	}
 
-	return "", fmt.Errorf("invalid response format from Claude, detail %v", claudeResp)
-}
+		var req WebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 
-func (bot *ChatBot) interpretAPIResponse(userMessage string, apiResp *ZahirResponse, endpoint string) (string, error) {
-	apiData, err := json.Marshal(apiResp)
-	if err != nil {
-		return "", err
-	}
+		p, err := bot.authenticate(r, req.BearerToken, req.Slug)
+		if err != nil {
+			writeAuthError(w, err)
+			return
+		}
 
-	prompt := userMessage
+		response := bot.ProcessMessage(auth.NewContext(r.Context(), p), req)
+		response.Message = cleanMessage(response.Message)
 
-	// add to cache
-	if string(apiData) != "" || string(apiData) != "[]" {
-		CacheData = CacheEntry{"data " + endpoint + ":" + string(apiData)}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
 	}
-
-	return bot.askClaudeFromAPIRes(prompt, endpoint, string(apiData))
 }
 
-// Main dan webhook handler tetap sama
-func webhookHandler(bot *ChatBot) http.HandlerFunc {
+// webhookStreamHandler sama dengan webhookHandler, tapi progres dan token jawaban dikirim lewat Server-Sent Events.
+// Event: progress, token, discard, dan done (berisi ZahirResponse lengkap)
+func webhookStreamHandler(bot *ChatBot) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -819,21 +752,83 @@ func webhookHandler(bot *ChatBot) http.HandlerFunc {
 			return
 		}
 
-		response := bot.ProcessMessage(req)
-		response.Message = strings.ReplaceAll(response.Message, "```html", "")
-		response.Message = strings.ReplaceAll(response.Message, "```", "")
-		response.Message = strings.ReplaceAll(response.Message, "``json", "")
+		p, err := bot.authenticate(r, req.BearerToken, req.Slug)
+		if err != nil {
+			writeAuthError(w, err)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+
+		emit := func(event string, data any) {
+			b, err := json.Marshal(data)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+			flusher.Flush()
+		}
+
+		response := bot.ProcessMessageStream(auth.NewContext(r.Context(), p), req, emit)
+		response.Message = cleanMessage(response.Message)
+		emit("done", response)
 	}
 }
 
+// cleanMessage membuang pembungkus markdown ``` dari jawaban AI
+func cleanMessage(msg string) string {
+	msg = strings.ReplaceAll(msg, "```html", "")
+	msg = strings.ReplaceAll(msg, "```", "")
+	msg = strings.ReplaceAll(msg, "``json", "")
+	return msg
+}
+
 func main() {
 	Init()
-	bot := NewChatBot()
+	// log ditulis lewat slog, termasuk log.Printf; token, API key dan PII disamarkan
+	redactor := logging.NewRedactor(RedactFields, APIKey, VisionAPIKey, BearerToken, LoginKey, MetricsToken)
+	slog.SetDefault(logging.New(os.Stderr, LogFormat, LogLevel, redactor))
+
+	httpClient := &http.Client{}
+	llm, err := ai.New(LLMProvider, APIUrl, APIKey, httpClient)
+	if err != nil {
+		log.Fatal(err)
+	}
+	vision, err := ai.New(VisionProvider, VisionAPIUrl, VisionAPIKey, httpClient)
+	if err != nil {
+		log.Fatal(err)
+	}
+	settings, err := tenant.NewStore(TenantSettings, defaultSettings())
+	if err != nil {
+		log.Fatalf("Invalid tenant settings: %v", err)
+	}
+	roles, err := auth.LoadRoles(AuthRoles)
+	if err != nil {
+		log.Fatalf("Invalid AUTH_ROLES: %v", err)
+	}
+	logins, err := newLogins()
+	if err != nil {
+		log.Fatalf("Invalid login store: %v", err)
+	}
+	bot := NewChatBot(llm, vision, settings, roles, logins)
 
 	http.HandleFunc("/webhook", webhookHandler(bot))
+	http.HandleFunc("/webhook/stream", webhookStreamHandler(bot))
+	http.HandleFunc(answer.FormAction, formHandler(bot))
+	http.HandleFunc("/settings", settingsHandler(bot))
+	http.HandleFunc("/auth/login", loginHandler(bot))
+	http.HandleFunc("/auth/logout", logoutHandler(bot))
+	http.HandleFunc("/auth/me", meHandler(bot))
+	http.HandleFunc("/metrics", metricsHandler(prometheus.DefaultGatherer))
 
 	// Serve the index.html file and inject WEBHOOK_URL from env
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -854,136 +849,8 @@ func main() {
 		w.Write([]byte(htmlStr))
 	})
 
-	log.Printf("Server starting on port %s", Port)
-	if err := http.ListenAndServe(Port, nil); err != nil {
+	slog.Info("server starting", "port", Port)
+	if err := http.ListenAndServe(Port, withRequestID(http.DefaultServeMux)); err != nil {
 		log.Fatal(err)
 	}
 }
-
-// Tambahkan versi baru getDataFromAPI yang menerima bearerToken dan slug
-func (bot *ChatBot) getDataFromAPIWithAuth(decision *APIDecision, bearerToken, slug string) (*ZahirResponse, error) {
-	params := url.Values{}
-	for key, value := range decision.Params {
-		params.Add(key, fmt.Sprintf("%v", value))
-	}
-
-	urlStr := fmt.Sprintf("%s/%s", BaseAPIURL, strings.TrimSpace(decision.Endpoint))
-	if len(params) > 0 {
-		urlStr = fmt.Sprintf("%s?%s", urlStr, params.Encode())
-	}
-
-	req, err := http.NewRequest("GET", urlStr, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", bearerToken))
-	req.Header.Add("slug", slug)
-	req.Header.Add("Content-Type", "application/json")
-
-	fmt.Println("========")
-	fmt.Printf("Request URL: %s\n", urlStr)
-	fmt.Printf("Request Headers: %v\n", req.Header)
-	fmt.Printf("Request Body: %s\n", params.Encode())
-	fmt.Println("========")
-
-	resp, err := bot.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var zahirResp ZahirResponse
-	switch decision.Endpoint {
-	case "contacts":
-		r := model.ContactResp{}
-		if err := grest.NewJSON(bodyBytes, true).ToFlat().Unmarshal(&r); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = r.Data
-	case "sales_invoices":
-		r := model.SalesInvoicesResp{}
-		if err := grest.NewJSON(bodyBytes, true).ToFlat().Unmarshal(&r); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = r.Data
-	case "products":
-		r := model.ProductResp{}
-		if err := grest.NewJSON(bodyBytes, true).ToFlat().Unmarshal(&r); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = r.Data
-	case "purchases_invoices":
-		r := model.PurchaseInvResp{}
-		if err := grest.NewJSON(bodyBytes, true).ToFlat().Unmarshal(&r); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = r.Data
-	case "dashboards/daily_sales":
-		var d interface{}
-		if err := json.Unmarshal(bodyBytes, &d); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = d
-	case "dashboards/balance_sheet_simple":
-		var d interface{}
-		if err := json.Unmarshal(bodyBytes, &d); err != nil {
-			return nil, err
-		}
-		zahirResp.Data = d
-	default:
-		if err := json.Unmarshal(bodyBytes, &zahirResp); err != nil {
-			var d interface{}
-			if err := json.Unmarshal(bodyBytes, &d); err != nil {
-				return nil, err
-			}
-			zahirResp.Data = d
-		}
-	}
-
-	return &zahirResp, nil
-}
-
-// Ubah postToAPI agar menerima bearerToken dan slug
-func (bot *ChatBot) postToAPI(endpoint string, params map[string]any, bearerToken, slug string) (ZahirResponse, error) {
-	zRes := ZahirResponse{}
-	fmt.Println("==== POST PAYLOAD====")
-	fmt.Println(params)
-	fmt.Println("==== POST PAYLOAD ====")
-
-	jsonData, err := json.Marshal(params)
-	if err != nil {
-		return zRes, err
-	}
-
-	req, err := http.NewRequest("POST", BaseAPIURL+"/"+endpoint, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return zRes, err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+bearerToken)
-	req.Header.Set("slug", slug)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := bot.client.Do(req)
-	if err != nil {
-		return zRes, err
-	}
-	defer resp.Body.Close()
-
-	if err := json.NewDecoder(resp.Body).Decode(&zRes); err != nil {
-		return zRes, err
-	}
-
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		zRes.Status = "OK"
-		zRes.Message = "Sukses input data"
-	}
-
-	return zRes, nil
-}
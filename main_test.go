@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestRowLimit(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		perPage  any
+		want     int
+	}{
+		{"sales_invoices", nil, DefaultRows},
+		{"contacts", nil, DefaultContactsRows},
+		{"products", "25", 25},
+		{"products", "0", DefaultRows},
+		{"products", "abc", DefaultRows},
+		{"sales_invoices", "5000", MaxRows},
+	}
+	for _, tt := range tests {
+		params := map[string]any{}
+		if tt.perPage != nil {
+			params["per_page"] = tt.perPage
+		}
+		if got := rowLimit(&APIDecision{Endpoint: tt.endpoint, Params: params}); got != tt.want {
+			t.Errorf("rowLimit(%s, per_page=%v) = %d, want %d", tt.endpoint, tt.perPage, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MaulanaR/zai/ai"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metric kesehatan bot dan pemakaian LLM, ditampilkan di /metrics bersama metric Zahir dari package zahir
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "zai_http_requests_total",
+		Help: "Jumlah request HTTP per route dan status, termasuk 401 dan 403.",
+	}, []string{"path", "status"})
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "zai_requests_total",
+		Help: "Jumlah pesan yang diproses per hasil: ok, clarify, pending_action, field_errors atau error.",
+	}, []string{"outcome"})
+	decisionParseFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "zai_decision_parse_failures_total",
+		Help: "Jumlah keputusan model yang gagal dibaca: tool_call (argumen tool tidak valid) atau answer (jawaban bukan JSON blocks).",
+	}, []string{"kind"})
+	llmRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "zai_llm_requests_total",
+		Help: "Jumlah request ke LLM per model dan hasil: tool_calls, answer atau error.",
+	}, []string{"model", "stage", "outcome"})
+	llmRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "zai_llm_request_duration_seconds",
+		Help:    "Durasi request ke LLM dalam detik, stage chat atau vision.",
+		Buckets: []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 80},
+	}, []string{"model", "stage"})
+	llmTokensTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "zai_llm_tokens_total",
+		Help: "Jumlah token LLM dari field usage per model, type prompt atau completion.",
+	}, []string{"model", "type"})
+	visionRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "zai_vision_requests_total",
+		Help: "Jumlah analisa gambar ke model vision per hasil: ok atau error.",
+	}, []string{"outcome"})
+)
+
+// observeLLM mencatat satu request ke LLM, aiResp nil jika request gagal
+func observeLLM(model, stage string, start time.Time, aiResp *ai.Response) {
+	llmRequestDuration.WithLabelValues(model, stage).Observe(time.Since(start).Seconds())
+	switch {
+	case aiResp == nil:
+		llmRequestsTotal.WithLabelValues(model, stage, "error").Inc()
+		return
+	case len(aiResp.ToolCalls) > 0:
+		llmRequestsTotal.WithLabelValues(model, stage, "tool_calls").Inc()
+	default:
+		llmRequestsTotal.WithLabelValues(model, stage, "answer").Inc()
+	}
+	llmTokensTotal.WithLabelValues(model, "prompt").Add(float64(aiResp.Usage.PromptTokens))
+	llmTokensTotal.WithLabelValues(model, "completion").Add(float64(aiResp.Usage.CompletionTokens))
+}
+
+// observeResult mencatat hasil satu pesan di zai_requests_total
+func observeResult(res *ZahirResponse) {
+	if res == nil {
+		return
+	}
+	outcome := "ok"
+	switch {
+	case res.Status != "OK":
+		outcome = "error"
+	case res.Clarify != nil:
+		outcome = "clarify"
+	case res.PendingAction != nil:
+		outcome = "pending_action"
+	case len(res.FieldErrors) > 0:
+		outcome = "field_errors"
+	}
+	requestsTotal.WithLabelValues(outcome).Inc()
+}
+
+// observeHTTP mencatat satu request HTTP. path berupa pattern route yang terdaftar (misal /webhook,
+// path tidak dikenal menjadi /) supaya jumlah kombinasi label tetap terbatas
+func observeHTTP(path string, status int) {
+	httpRequestsTotal.WithLabelValues(path, strconv.Itoa(status)).Inc()
+}
+
+// metricsHandler GET /metrics dalam format teks Prometheus dari gatherer g (di main: prometheus.DefaultGatherer).
+// Jika METRICS_TOKEN diisi, scraper wajib mengirim header Authorization: Bearer <METRICS_TOKEN>
+func metricsHandler(g prometheus.Gatherer) http.HandlerFunc {
+	handler := promhttp.HandlerFor(g, promhttp.HandlerOpts{})
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if MetricsToken != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(MetricsToken)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="zai-metrics"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MaulanaR/zai/ai"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	// registry per test dan counter dikosongkan supaya nilai absolut tetap benar pada go test -count=N
+	reg := prometheus.NewRegistry()
+	for _, c := range []interface {
+		prometheus.Collector
+		Reset()
+	}{httpRequestsTotal, requestsTotal, llmRequestsTotal, llmRequestDuration, llmTokensTotal} {
+		c.Reset()
+		reg.MustRegister(c)
+	}
+
+	observeLLM("gpt-test", "chat", time.Now(), &ai.Response{
+		ToolCalls: []ai.ToolCall{{ID: "call_1", Name: "list_contacts"}},
+		Usage:     ai.Usage{PromptTokens: 120, CompletionTokens: 30},
+	})
+	observeLLM("gpt-test", "chat", time.Now(), nil)
+	observeResult(&ZahirResponse{Status: "OK", Clarify: &Clarify{Question: "Periode mana?"}})
+	observeHTTP("/webhook", http.StatusUnauthorized)
+
+	defer func(token string) { MetricsToken = token }(MetricsToken)
+	MetricsToken = "scrape-secret"
+
+	rec := httptest.NewRecorder()
+	metricsHandler(reg)(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("tanpa token status = %d, want 401", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-secret")
+	metricsHandler(reg)(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(rec.Body)
+	if err != nil {
+		t.Fatalf("output /metrics tidak valid: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"zai_llm_tokens_total", map[string]string{"model": "gpt-test", "type": "prompt"}, 120},
+		{"zai_llm_tokens_total", map[string]string{"model": "gpt-test", "type": "completion"}, 30},
+		{"zai_llm_requests_total", map[string]string{"model": "gpt-test", "stage": "chat", "outcome": "tool_calls"}, 1},
+		{"zai_llm_requests_total", map[string]string{"model": "gpt-test", "stage": "chat", "outcome": "error"}, 1},
+		{"zai_llm_request_duration_seconds", map[string]string{"model": "gpt-test", "stage": "chat"}, 2},
+		{"zai_requests_total", map[string]string{"outcome": "clarify"}, 1},
+		{"zai_http_requests_total", map[string]string{"path": "/webhook", "status": "401"}, 1},
+	}
+	for _, tt := range tests {
+		mf, ok := families[tt.name]
+		if !ok {
+			t.Errorf("metric %s tidak ada di /metrics", tt.name)
+			continue
+		}
+		m := find(mf, tt.labels)
+		if m == nil {
+			t.Errorf("%s%v tidak ada di /metrics", tt.name, tt.labels)
+			continue
+		}
+		got := m.GetCounter().GetValue()
+		if mf.GetType() == dto.MetricType_HISTOGRAM {
+			got = float64(m.GetHistogram().GetSampleCount())
+		}
+		if got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+}
+
+// find series dengan semua label yang diminta
+func find(mf *dto.MetricFamily, labels map[string]string) *dto.Metric {
+	for _, m := range mf.GetMetric() {
+		match := 0
+		for _, l := range m.GetLabel() {
+			if labels[l.GetName()] == l.GetValue() {
+				match++
+			}
+		}
+		if match == len(labels) {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/MaulanaR/zai/logging"
+)
+
+// RequestIDHeader header request ID, dikirim balik di response dan diteruskan ke Zahir
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID request ID dari client hanya dipakai jika aman ditulis ke log dan header
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// withRequestID memberi setiap request ID (dari header X-Request-ID client atau dibuat baru), menyimpannya
+// di context untuk semua log selama request, mengembalikannya sebagai header response, lalu menulis
+// satu log berisi status dan durasi request serta mencatat metric zai_http_requests_total
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = logging.NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := logging.WithRequestID(r.Context(), id)
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		slog.InfoContext(ctx, "http request", "method", r.Method, "path", r.URL.Path,
+			"status", rec.status, "duration_ms", time.Since(start).Milliseconds())
+		if mux, ok := next.(*http.ServeMux); ok {
+			_, pattern := mux.Handler(r)
+			observeHTTP(pattern, rec.status)
+		}
+	})
+}
+
+// statusRecorder mencatat status code response, Flush tetap diteruskan untuk /webhook/stream
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -8,6 +8,7 @@ type ContactResp struct {
 	Data []Contact `json:"results"`
 }
 type Contact struct {
+	ID                   grest.NullUUID   `json:"id"`
 	Name                 grest.NullString `json:"name"`
 	Note                 grest.NullText   `json:"note"`
 	NationalIDNumber     grest.NullString `json:"national_id_number"`
@@ -66,6 +67,7 @@ type ProductResp struct {
 	Data []Product `json:"results"`
 }
 type Product struct {
+	ID              grest.NullUUID    `json:"id"`
 	Code            grest.NullString  `json:"code"`
 	Name            grest.NullString  `json:"name"`
 	Description     grest.NullString  `json:"description"`
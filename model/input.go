@@ -0,0 +1,19 @@
+package model
+
+// CreateContactInput payload POST contacts, field wajib sama dengan aturan input kontak di Zahir.
+// Tag label dipakai sebagai label form input
+type CreateContactInput struct {
+	Name       string `json:"name" label:"Nama" validate:"required"`
+	Phone      string `json:"phone" label:"Telepon" validate:"required"`
+	Email      string `json:"email" label:"Email" validate:"required,email"`
+	IsCustomer bool   `json:"is_customer,omitempty" label:"Customer"`
+	IsSupplier bool   `json:"is_supplier,omitempty" label:"Supplier"`
+	IsEmployee bool   `json:"is_employee,omitempty" label:"Karyawan"`
+}
+
+// CreateProductInput payload POST products, Price pointer supaya harga 0 tetap dianggap terisi
+type CreateProductInput struct {
+	Name     string   `json:"name" label:"Nama" validate:"required"`
+	Price    *float64 `json:"price" label:"Harga" validate:"required,gte=0"`
+	Category string   `json:"category" label:"Kategori" validate:"required"`
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/MaulanaR/zai/action"
+	"github.com/MaulanaR/zai/answer"
+	"github.com/MaulanaR/zai/auth"
+	"github.com/MaulanaR/zai/invoice"
+	"github.com/MaulanaR/zai/resolve"
+	"github.com/MaulanaR/zai/session"
+	"github.com/MaulanaR/zai/validation"
+	"github.com/MaulanaR/zai/zahir"
+)
+
+var (
+	confirmWords = []string{"ya", "iya", "y", "ok", "oke", "setuju", "simpan", "konfirmasi", "lanjut", "yes", "confirm"}
+	cancelWords  = []string{"batal", "batalkan", "tidak", "gak", "enggak", "jangan", "no", "cancel"}
+	// deleteWords konfirmasi lewat chat untuk aksi hapus, "ya" saja tidak cukup
+	deleteWords = []string{"hapus", "ya hapus", "hapus saja", "delete"}
+)
+
+// draftInvoice menyusun draft faktur dari tool call lalu menyimpannya sebagai aksi yang menunggu konfirmasi
+func (bot *ChatBot) draftInvoice(ctx context.Context, sess *session.Session, kind invoice.Kind, decision *APIDecision, bearerToken, slug string) *ZahirResponse {
+	settings := bot.settings.Get(slug)
+	in := invoice.InputFromParams(decision.Params, kind.Party+"_name")
+	draft, err := invoice.Build(ctx, bot.entities, bot.zahirClient(bearerToken, slug), kind, in, settings.Now())
+	if err != nil {
+		return &ZahirResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Gagal menyusun draft %s: %v", strings.ToLower(kind.Title), err),
+		}
+	}
+
+	prep := "untuk"
+	if kind.Party == "supplier" {
+		prep = "dari"
+	}
+	body := draft.Body()
+	res := bot.previewAction(sess, &action.Action{
+		Method:   http.MethodPost,
+		Endpoint: kind.Endpoint,
+		Summary:  fmt.Sprintf("%s %s %s sebesar %s", kind.Title, prep, draft.PartyName, settings.Money(draft.TotalAmount, "")),
+		Payload:  body,
+		Diff:     action.Diff(nil, body),
+		Data:     draft,
+	}, draft.Blocks())
+	res.Data = draft
+	return res
+}
+
+// previewChange mencari data yang akan diubah/dihapus lewat GET, lalu menyimpan aksi PATCH/DELETE
+// dengan diff field sebelum dan sesudah
+func (bot *ChatBot) previewChange(ctx context.Context, sess *session.Session, decision *APIDecision, bearerToken, slug string) *ZahirResponse {
+	client := bot.zahirClient(bearerToken, slug)
+
+	var (
+		found resolve.Candidate
+		label string
+		err   error
+	)
+	switch decision.Endpoint {
+	case "contacts":
+		label = "kontak"
+		found, err = bot.entities.One(ctx, client, resolve.Contact, decision.Target)
+	case "products":
+		label = "produk"
+		found, err = bot.entities.One(ctx, client, resolve.Product, decision.Target)
+	default:
+		err = fmt.Errorf("endpoint %s belum didukung", decision.Endpoint)
+	}
+	if err != nil {
+		return &ZahirResponse{Status: "error", Message: fmt.Sprintf("Gagal mencari data: %v", err)}
+	}
+	if found.ID == "" {
+		return &ZahirResponse{Status: "error", Message: fmt.Sprintf("Gagal mencari data: %s %q tidak memiliki ID", label, decision.Target)}
+	}
+
+	record := found.Record
+	a := &action.Action{Method: decision.Method, Endpoint: decision.Endpoint, RecordID: found.ID}
+	if decision.Method == http.MethodDelete {
+		before := map[string]any{}
+		for k, v := range record {
+			if k != "id" {
+				before[k] = v
+			}
+		}
+		a.Summary = fmt.Sprintf("Hapus %s %v", label, record["name"])
+		a.Diff = action.Diff(before, nil)
+	} else {
+		// nilai lama diambil langsung dari Zahir: record di cache resolver dibangun dari model
+		// yang tidak memuat semua field (misal phone dan email)
+		current, err := client.Get(ctx, decision.Endpoint, found.ID)
+		if err != nil {
+			return &ZahirResponse{Status: "error", Message: fmt.Sprintf("Gagal mengambil data %s %v: %v", label, record["name"], err)}
+		}
+		before := map[string]any{}
+		for k := range decision.Params {
+			before[k] = current[k]
+		}
+		a.Summary = fmt.Sprintf("Ubah %s %v", label, record["name"])
+		a.Payload = decision.Params
+		a.Diff = action.Diff(before, decision.Params)
+		if len(a.Diff) == 0 {
+			return &ZahirResponse{Status: "OK", Message: fmt.Sprintf("Tidak ada perubahan untuk %s %v", label, record["name"])}
+		}
+	}
+	return bot.previewAction(sess, a, nil)
+}
+
+// previewAction menyimpan aksi tulis dan mengembalikan preview beserta pending_action_id.
+// Jika blocks nil, preview berupa tabel diff
+func (bot *ChatBot) previewAction(sess *session.Session, a *action.Action, blocks []answer.Block) *ZahirResponse {
+	a.SessionID = sess.ID
+	bot.actions.Add(a)
+
+	if blocks == nil {
+		blocks = diffBlocks(a)
+	}
+	hint := `Balas "ya" untuk menyimpan atau "batal" untuk membatalkan.`
+	if a.Destructive() {
+		hint = `Data akan dihapus permanen. Balas "hapus" untuk melanjutkan atau "batal" untuk membatalkan.`
+	}
+	blocks = append(blocks, answer.Block{
+		Type: answer.TypeText,
+		Text: fmt.Sprintf("%s Berlaku sampai %s.", hint, a.ExpiresAt.Format("15:04")),
+	})
+	return &ZahirResponse{
+		Status:        "OK",
+		Message:       answer.PlainText(blocks),
+		Blocks:        blocks,
+		PendingAction: a,
+	}
+}
+
+// diffBlocks tampilan perubahan field sebelum dan sesudah
+func diffBlocks(a *action.Action) []answer.Block {
+	rows := [][]any{}
+	for _, c := range a.Diff {
+		rows = append(rows, []any{c.Field, cell(c.Before), cell(c.After)})
+	}
+	return []answer.Block{
+		{Type: answer.TypeText, Text: a.Summary},
+		{Type: answer.TypeTable, Table: &answer.Table{
+			Columns: []string{"Field", "Sebelum", "Sesudah"},
+			Rows:    rows,
+		}},
+	}
+}
+
+// cell nilai untuk block table, selain string/angka/boolean diubah menjadi teks
+func cell(v any) any {
+	switch v.(type) {
+	case nil, string, float64, bool:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// handleDecision memproses konfirmasi/pembatalan berdasarkan pending_action_id, atau balasan "ya"/"batal"
+// atas aksi terakhir di session. Aksi hapus hanya dijalankan dengan ID atau balasan "hapus".
+// Nil jika request bukan keputusan atas aksi
+func (bot *ChatBot) handleDecision(ctx context.Context, sess *session.Session, req WebhookRequest, bearerToken, slug string) *ZahirResponse {
+	id, confirm := req.Confirm, true
+	if req.Cancel != "" {
+		id, confirm = req.Cancel, false
+	}
+	if id == "" {
+		latest := bot.actions.Latest(sess.ID)
+		switch {
+		case latest == nil:
+			return nil
+		case matchWord(req.Message, cancelWords):
+			id, confirm = latest.ID, false
+		case latest.Destructive() && matchWord(req.Message, deleteWords):
+			id = latest.ID
+		case matchWord(req.Message, confirmWords):
+			if latest.Destructive() {
+				return &ZahirResponse{
+					Status:        "OK",
+					Message:       fmt.Sprintf(`%s tidak bisa dibatalkan. Balas "hapus" untuk melanjutkan atau "batal" untuk membatalkan.`, latest.Summary),
+					PendingAction: latest,
+				}
+			}
+			id = latest.ID
+		default:
+			return nil
+		}
+	}
+
+	if !confirm {
+		a, err := bot.actions.Cancel(id, sess.ID)
+		if err != nil {
+			return &ZahirResponse{Status: "error", Message: fmt.Sprintf("Gagal membatalkan: %v", err)}
+		}
+		return &ZahirResponse{Status: "OK", Message: fmt.Sprintf("%s dibatalkan", a.Summary)}
+	}
+
+	if p := auth.FromContext(ctx); !p.Can(auth.Write) {
+		return forbidden(p, "menyimpan perubahan data")
+	}
+	a, err := bot.actions.Take(id, sess.ID)
+	if err != nil {
+		return &ZahirResponse{Status: "error", Message: fmt.Sprintf("Gagal konfirmasi: %v", err)}
+	}
+	return bot.execute(ctx, a, bearerToken, slug)
+}
+
+// execute mengirim aksi yang sudah dikonfirmasi ke Zahir API
+func (bot *ChatBot) execute(ctx context.Context, a *action.Action, bearerToken, slug string) *ZahirResponse {
+	client := bot.zahirClient(bearerToken, slug)
+
+	var (
+		result map[string]any
+		err    error
+	)
+	switch a.Method {
+	case http.MethodPost:
+		result, err = client.Create(ctx, a.Endpoint, a.Payload)
+	case http.MethodPatch:
+		result, err = client.Patch(ctx, a.Endpoint, a.RecordID, a.Payload)
+	case http.MethodPut:
+		result, err = client.Put(ctx, a.Endpoint, a.RecordID, a.Payload)
+	case http.MethodDelete:
+		result, err = client.Delete(ctx, a.Endpoint, a.RecordID)
+	default:
+		err = fmt.Errorf("method %s belum didukung", a.Method)
+	}
+
+	// jika errornya dari Zahir (validasi dll) untuk input kontak/produk, maka kembalikan form berisi payload untuk diperbaiki
+	var apiErr *zahir.APIError
+	if errors.As(err, &apiErr) && a.Form != "" {
+		return inputForm(a.Form, toMap(a.Payload), apiFieldErrors(apiErr), fmt.Sprintf("Zahir menolak data %s", inputSchemas[a.Form].Label))
+	}
+	if err != nil {
+		return &ZahirResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Gagal menyimpan %s: %v", a.Summary, err),
+		}
+	}
+
+	// nama kontak/produk berubah, cache resolver slug ini harus diambil ulang
+	if a.Endpoint == "contacts" || a.Endpoint == "products" {
+		bot.entities.Invalidate(slug)
+	}
+
+	message := fmt.Sprintf("%s berhasil disimpan", a.Summary)
+	if a.Destructive() {
+		message = fmt.Sprintf("%s berhasil", a.Summary)
+	}
+	return &ZahirResponse{
+		Status:  "OK",
+		Message: message,
+		Data:    result,
+	}
+}
+
+// apiFieldErrors mengubah detail error Zahir ({"field": "pesan"}) menjadi daftar field error
+func apiFieldErrors(e *zahir.APIError) validation.Errors {
+	detail, _ := e.Detail.(map[string]any)
+	if len(detail) == 0 {
+		return validation.Errors{{Rule: "zahir", Message: e.Message}}
+	}
+	errs := validation.Errors{}
+	for field, msg := range detail {
+		errs = append(errs, validation.FieldError{Field: field, Rule: "zahir", Message: fmt.Sprintf("%s %v", field, msg)})
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+// matchWord true jika pesan hanya berisi salah satu kata (tanpa tanda baca)
+func matchWord(message string, words []string) bool {
+	m := strings.ToLower(strings.Trim(strings.TrimSpace(message), ".!?, "))
+	for _, w := range words {
+		if m == w {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MaulanaR/zai/tenant"
+)
+
+func TestPreviewChangeUsesCurrentRecord(t *testing.T) {
+	const id = "6513270e-269e-4d37-b2a7-4de452e6b438"
+	zahirSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/contacts":
+			// list tidak memuat phone dan email, sama seperti model.Contact
+			w.Write([]byte(`{"results": [{"id": "` + id + `", "name": "Budi Santoso", "is_customer": true}], "total_pages": 1}`))
+		case "/contacts/" + id:
+			w.Write([]byte(`{"results": {"id": "` + id + `", "name": "Budi Santoso", "phone": "08111111", "email": "budi@contoh.id", "is_customer": true}}`))
+		case "/products":
+			w.Write([]byte(`{"results": [], "total_pages": 1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer zahirSrv.Close()
+
+	defer func(url string) { BaseAPIURL = url }(BaseAPIURL)
+	BaseAPIURL = zahirSrv.URL
+
+	settings, err := tenant.NewStore("", defaultSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := NewChatBot(nil, nil, settings, nil, nil)
+	decision := &APIDecision{
+		Method:   http.MethodPatch,
+		Endpoint: "contacts",
+		Target:   "budi",
+		Params:   map[string]any{"phone": "08222222", "email": "budi@contoh.id"},
+	}
+
+	res := bot.previewChange(context.Background(), bot.sessions.Get("s1"), decision, "tok", "s")
+
+	if res.Status != "OK" || res.PendingAction == nil {
+		t.Fatalf("response = %s %q, want preview pending action", res.Status, res.Message)
+	}
+	diff := res.PendingAction.Diff
+	if len(diff) != 1 || diff[0].Field != "phone" || diff[0].Before != "08111111" || diff[0].After != "08222222" {
+		t.Errorf("diff = %+v, want hanya phone 08111111 -> 08222222", diff)
+	}
+}
@@ -0,0 +1,254 @@
+// Package period mengubah ungkapan periode relatif bahasa Indonesia dan Inggris ("bulan lalu",
+// "minggu ini", "Q3 tahun lalu", "kemarin", "last 7 days") menjadi rentang tanggal. Perhitungan
+// memakai zona waktu dari waktu acuan yang diberikan, sehingga hasilnya tidak bergantung pada model.
+package period
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout format tanggal yang dipakai Zahir API
+const Layout = "2006-01-02"
+
+// Range rentang tanggal inklusif, From dan To jam 00:00 di zona waktu acuan
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+// errSkip aturan tidak cocok, aturan berikutnya dicoba
+var errSkip = errors.New("skip")
+
+// Parse mengubah ungkapan periode menjadi rentang tanggal relatif terhadap now. Minggu dimulai
+// hari Senin; bulan atau tanggal tanpa tahun berarti yang terakhir sudah lewat atau sedang berjalan.
+func Parse(expr string, now time.Time) (Range, error) {
+	s := normalize(expr)
+	if s == "" {
+		return Range{}, fmt.Errorf("periode kosong")
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if r, err := single(s, today); !errors.Is(err, errSkip) {
+		return r, err
+	}
+
+	// "sejak maret", "since last month": sampai hari ini
+	for _, p := range []string{"sejak ", "mulai ", "since "} {
+		if rest, ok := strings.CutPrefix(s, p); ok {
+			r, err := single(rest, today)
+			if errors.Is(err, errSkip) {
+				break
+			}
+			if err != nil {
+				return Range{}, err
+			}
+			return check(Range{From: r.From, To: today})
+		}
+	}
+
+	// "januari sampai maret", "between 1 march and 15 march"
+	for _, p := range []string{"dari ", "antara ", "from ", "between "} {
+		s = strings.TrimPrefix(s, p)
+	}
+	for _, sep := range []string{" sampai ", " hingga ", " to ", " until ", " through ", " - ", " dan ", " and "} {
+		a, b, ok := strings.Cut(s, sep)
+		if !ok {
+			continue
+		}
+		// sisi kanan dibaca dulu supaya bulan dan tahunnya bisa dipakai sisi kiri
+		to, errTo := single(b, today)
+		if errors.Is(errTo, errSkip) {
+			continue
+		}
+		from, err := start(a, b, to, errTo == nil, today)
+		if errors.Is(err, errSkip) {
+			continue
+		}
+		if err != nil {
+			return Range{}, err
+		}
+		if errTo != nil {
+			return Range{}, errTo
+		}
+		return check(Range{From: from.From, To: to.To})
+	}
+	return Range{}, fmt.Errorf("periode %q tidak dikenali", expr)
+}
+
+// namedYear ungkapan yang menyebut tahun secara eksplisit
+var namedYear = regexp.MustCompile(vocab.Replace(`(?:^| ){year}$|\d{4}`))
+
+// start sisi kiri rentang a. Tanggal tanpa bulan ("1 - 15 maret") memakai bulan dan tahun sisi kanan,
+// dan jika sisi kanan b menyebut tahun ("maret - mei 2025") tahun itu dipakai sisi kiri yang tidak menyebutnya
+func start(a, b string, to Range, ok bool, today time.Time) (Range, error) {
+	d, err := strconv.Atoi(a)
+	bareDay := err == nil && d >= 1 && d <= 31
+	switch {
+	case !ok && bareDay:
+		// error sisi kanan yang dilaporkan
+		return Range{}, nil
+	case !ok:
+		return single(a, today)
+	}
+	y := strconv.Itoa(to.To.Year())
+	if bareDay {
+		return day(a, y, strconv.Itoa(int(to.To.Month())), a, today)
+	}
+	if namedYear.MatchString(b) && !namedYear.MatchString(a) {
+		if r, err := single(a+" "+y, today); !errors.Is(err, errSkip) {
+			return r, err
+		}
+	}
+	return single(a, today)
+}
+
+// single mencocokkan satu ungkapan tanpa rentang, errSkip jika tidak ada aturan yang cocok
+func single(s string, today time.Time) (Range, error) {
+	if n, ok := dayWords[s]; ok {
+		d := today.AddDate(0, 0, n)
+		return Range{From: d, To: d}, nil
+	}
+	if r, err := edge(s, today); !errors.Is(err, errSkip) {
+		return r, err
+	}
+	for _, r := range rules {
+		m := r.re.FindStringSubmatch(s)
+		if m == nil {
+			continue
+		}
+		res, err := r.fn(m, today)
+		if errors.Is(err, errSkip) {
+			continue
+		}
+		return res, err
+	}
+	return Range{}, errSkip
+}
+
+func check(r Range) (Range, error) {
+	if r.From.After(r.To) {
+		return Range{}, fmt.Errorf("awal periode %s setelah akhir periode %s", r.From.Format(Layout), r.To.Format(Layout))
+	}
+	return r, nil
+}
+
+// Unit satuan periode kalender
+type Unit int
+
+const (
+	Day Unit = iota
+	Week
+	Month
+	Quarter
+	Half
+	Year
+)
+
+// Calendar periode kalender ke-n dari periode yang memuat today, n negatif untuk periode sebelumnya
+func Calendar(u Unit, today time.Time, n int) Range {
+	y, m, loc := today.Year(), today.Month(), today.Location()
+	var from time.Time
+	switch u {
+	case Day:
+		from = today.AddDate(0, 0, n)
+		return Range{From: from, To: from}
+	case Week:
+		from = today.AddDate(0, 0, -weekdayIndex(today)+7*n)
+		return Range{From: from, To: from.AddDate(0, 0, 6)}
+	case Month:
+		from = time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, loc)
+		return Range{From: from, To: from.AddDate(0, 1, -1)}
+	case Quarter:
+		from = time.Date(y, time.Month((int(m)-1)/3*3+1+3*n), 1, 0, 0, 0, 0, loc)
+		return Range{From: from, To: from.AddDate(0, 3, -1)}
+	case Half:
+		from = time.Date(y, time.Month((int(m)-1)/6*6+1+6*n), 1, 0, 0, 0, 0, loc)
+		return Range{From: from, To: from.AddDate(0, 6, -1)}
+	default:
+		from = time.Date(y+n, 1, 1, 0, 0, 0, 0, loc)
+		return Range{From: from, To: from.AddDate(1, 0, -1)}
+	}
+}
+
+// Rolling n satuan terakhir sampai hari ini (n positif) atau n satuan ke depan mulai hari ini (n negatif)
+func Rolling(u Unit, today time.Time, n int) Range {
+	if n < 0 {
+		return Range{From: today, To: shift(u, today, -n).AddDate(0, 0, -1)}
+	}
+	return Range{From: shift(u, today, -n).AddDate(0, 0, 1), To: today}
+}
+
+// ToDate awal periode kalender berjalan sampai hari ini, misal year to date
+func ToDate(u Unit, today time.Time) Range {
+	return Range{From: Calendar(u, today, 0).From, To: today}
+}
+
+func shift(u Unit, t time.Time, n int) time.Time {
+	switch u {
+	case Day:
+		return t.AddDate(0, 0, n)
+	case Week:
+		return t.AddDate(0, 0, 7*n)
+	case Month:
+		return addMonths(t, n)
+	case Quarter:
+		return addMonths(t, 3*n)
+	case Half:
+		return addMonths(t, 6*n)
+	default:
+		return addMonths(t, 12*n)
+	}
+}
+
+// addMonths seperti AddDate tapi tanggal dipotong ke akhir bulan, 31 Maret - 1 bulan = 28/29 Februari
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), last)-1)
+}
+
+// weekdayIndex 0 untuk Senin sampai 6 untuk Minggu
+func weekdayIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+var bulan = []string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// Label nama periode dalam bahasa Indonesia, misal "September 2026", "Q3 2025" atau "1 - 15 Maret 2026"
+func (r Range) Label() string {
+	if r.From.Equal(r.To) {
+		return dayLabel(r.From)
+	}
+	wholeMonths := r.From.Day() == 1 && r.To.AddDate(0, 0, 1).Day() == 1
+	fy, fm, _ := r.From.Date()
+	ty, tm, _ := r.To.Date()
+	span := (ty-fy)*12 + int(tm-fm) + 1
+	switch {
+	case wholeMonths && span == 1:
+		return fmt.Sprintf("%s %d", bulan[fm-1], fy)
+	case wholeMonths && span == 12 && fm == time.January:
+		return fmt.Sprint(fy)
+	case wholeMonths && span == 3 && (fm-1)%3 == 0:
+		return fmt.Sprintf("Q%d %d", (fm-1)/3+1, fy)
+	case wholeMonths && span == 6 && (fm-1)%6 == 0:
+		return fmt.Sprintf("Semester %d %d", (fm-1)/6+1, fy)
+	case wholeMonths && fy == ty:
+		return fmt.Sprintf("%s - %s %d", bulan[fm-1], bulan[tm-1], fy)
+	case wholeMonths:
+		return fmt.Sprintf("%s %d - %s %d", bulan[fm-1], fy, bulan[tm-1], ty)
+	case fy == ty && fm == tm:
+		return fmt.Sprintf("%d - %s", r.From.Day(), dayLabel(r.To))
+	case fy == ty:
+		return fmt.Sprintf("%d %s - %s", r.From.Day(), bulan[fm-1], dayLabel(r.To))
+	}
+	return dayLabel(r.From) + " - " + dayLabel(r.To)
+}
+
+func dayLabel(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), bulan[t.Month()-1], t.Year())
+}
@@ -0,0 +1,251 @@
+package period
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var wib = time.FixedZone("WIB", 7*60*60)
+
+// now waktu acuan tetap: Minggu 18 Oktober 2026 siang, pekan ini 12-18 Oktober
+var now = time.Date(2026, 10, 18, 14, 30, 0, 0, wib)
+
+func date(s string) time.Time {
+	t, err := time.ParseInLocation(Layout, s, wib)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParse(t *testing.T) {
+	jan10 := time.Date(2026, 1, 10, 9, 0, 0, 0, wib) // Sabtu, awal tahun
+	jan1 := time.Date(2026, 1, 1, 8, 0, 0, 0, wib)
+	fri := time.Date(2026, 10, 16, 9, 0, 0, 0, wib)
+
+	tests := []struct {
+		expr     string
+		now      time.Time // kosong berarti now
+		from, to string
+	}{
+		// hari relatif
+		{expr: "hari ini", from: "2026-10-18", to: "2026-10-18"},
+		{expr: "today", from: "2026-10-18", to: "2026-10-18"},
+		{expr: "kemarin", from: "2026-10-17", to: "2026-10-17"},
+		{expr: "yesterday", from: "2026-10-17", to: "2026-10-17"},
+		{expr: "kemarin lusa", from: "2026-10-16", to: "2026-10-16"},
+		{expr: "besok", from: "2026-10-19", to: "2026-10-19"},
+		{expr: "lusa", from: "2026-10-20", to: "2026-10-20"},
+		{expr: "kemarin", now: jan1, from: "2025-12-31", to: "2025-12-31"},
+
+		// {unit} {rel}
+		{expr: "bulan lalu", from: "2026-09-01", to: "2026-09-30"},
+		{expr: "Bulan ini", from: "2026-10-01", to: "2026-10-31"},
+		{expr: "bulan depan", from: "2026-11-01", to: "2026-11-30"},
+		{expr: "minggu ini", from: "2026-10-12", to: "2026-10-18"},
+		{expr: "minggu lalu", from: "2026-10-05", to: "2026-10-11"},
+		{expr: "pekan depan", from: "2026-10-19", to: "2026-10-25"},
+		{expr: "kuartal ini", from: "2026-10-01", to: "2026-12-31"},
+		{expr: "triwulan lalu", from: "2026-07-01", to: "2026-09-30"},
+		{expr: "semester lalu", from: "2026-01-01", to: "2026-06-30"},
+		{expr: "tahun lalu", from: "2025-01-01", to: "2025-12-31"},
+		{expr: "tahun depan", from: "2027-01-01", to: "2027-12-31"},
+
+		// {rel} {unit}
+		{expr: "last month", from: "2026-09-01", to: "2026-09-30"},
+		{expr: "this week", from: "2026-10-12", to: "2026-10-18"},
+		{expr: "next quarter", from: "2027-01-01", to: "2027-03-31"},
+		{expr: "previous year", from: "2025-01-01", to: "2025-12-31"},
+
+		// N {unit} lalu / lagi
+		{expr: "3 bulan lalu", from: "2026-07-01", to: "2026-07-31"},
+		{expr: "tiga bulan yang lalu", from: "2026-07-01", to: "2026-07-31"},
+		{expr: "2 weeks ago", from: "2026-09-28", to: "2026-10-04"},
+		{expr: "2 minggu lagi", from: "2026-10-26", to: "2026-11-01"},
+
+		// N {unit} terakhir, last/past N
+		{expr: "7 hari terakhir", from: "2026-10-12", to: "2026-10-18"},
+		{expr: "30 hari belakangan ini", from: "2026-09-19", to: "2026-10-18"},
+		{expr: "tiga puluh hari terakhir", from: "2026-09-19", to: "2026-10-18"},
+		{expr: "3 bulan terakhir", from: "2026-07-19", to: "2026-10-18"},
+		{expr: "sebulan terakhir", from: "2026-09-19", to: "2026-10-18"},
+		{expr: "last 30 days", from: "2026-09-19", to: "2026-10-18"},
+		{expr: "past 3 months", from: "2026-07-19", to: "2026-10-18"},
+		{expr: "past week", from: "2026-10-12", to: "2026-10-18"},
+
+		// N {unit} ke depan
+		{expr: "7 hari ke depan", from: "2026-10-18", to: "2026-10-24"},
+		{expr: "next 14 days", from: "2026-10-18", to: "2026-10-31"},
+
+		// to date
+		{expr: "tahun berjalan", from: "2026-01-01", to: "2026-10-18"},
+		{expr: "month to date", from: "2026-10-01", to: "2026-10-18"},
+		{expr: "ytd", from: "2026-01-01", to: "2026-10-18"},
+		{expr: "MTD", from: "2026-10-01", to: "2026-10-18"},
+		{expr: "qtd", from: "2026-10-01", to: "2026-10-18"},
+		{expr: "wtd", from: "2026-10-12", to: "2026-10-18"},
+		{expr: "ytd", now: jan10, from: "2026-01-01", to: "2026-01-10"},
+
+		// kuartal
+		{expr: "Q1", from: "2026-01-01", to: "2026-03-31"},
+		{expr: "Q2", from: "2026-04-01", to: "2026-06-30"},
+		{expr: "Q3", from: "2026-07-01", to: "2026-09-30"},
+		{expr: "Q4", from: "2026-10-01", to: "2026-12-31"},
+		{expr: "Q1 tahun lalu", from: "2025-01-01", to: "2025-03-31"},
+		{expr: "Q2 tahun lalu", from: "2025-04-01", to: "2025-06-30"},
+		{expr: "kuartal 3 tahun lalu", from: "2025-07-01", to: "2025-09-30"},
+		{expr: "q4 tahun lalu", from: "2025-10-01", to: "2025-12-31"},
+		{expr: "Q3 2024", from: "2024-07-01", to: "2024-09-30"},
+		{expr: "triwulan ke-2 2025", from: "2025-04-01", to: "2025-06-30"},
+		{expr: "third quarter of last year", from: "2025-07-01", to: "2025-09-30"},
+		{expr: "Q4", now: jan10, from: "2025-10-01", to: "2025-12-31"},
+		{expr: "Q1", now: jan10, from: "2026-01-01", to: "2026-03-31"},
+
+		// semester
+		{expr: "semester 1", from: "2026-01-01", to: "2026-06-30"},
+		{expr: "semester 2", from: "2026-07-01", to: "2026-12-31"},
+		{expr: "H2 2024", from: "2024-07-01", to: "2024-12-31"},
+		{expr: "first half of this year", from: "2026-01-01", to: "2026-06-30"},
+
+		// tanggal
+		{expr: "2024-03-05", from: "2024-03-05", to: "2024-03-05"},
+		{expr: "05/03/2024", from: "2024-03-05", to: "2024-03-05"},
+		{expr: "5 maret 2024", from: "2024-03-05", to: "2024-03-05"},
+		{expr: "tanggal 5 maret", from: "2026-03-05", to: "2026-03-05"},
+		{expr: "25 desember", from: "2025-12-25", to: "2025-12-25"},
+		{expr: "17 agustus tahun lalu", from: "2025-08-17", to: "2025-08-17"},
+		{expr: "march 5th 2024", from: "2024-03-05", to: "2024-03-05"},
+
+		// bulan
+		{expr: "03/2024", from: "2024-03-01", to: "2024-03-31"},
+		{expr: "maret", from: "2026-03-01", to: "2026-03-31"},
+		{expr: "desember", from: "2025-12-01", to: "2025-12-31"},
+		{expr: "bulan maret 2024", from: "2024-03-01", to: "2024-03-31"},
+		{expr: "maret tahun lalu", from: "2025-03-01", to: "2025-03-31"},
+		{expr: "maret lalu", from: "2026-03-01", to: "2026-03-31"},
+		{expr: "oktober lalu", from: "2025-10-01", to: "2025-10-31"},
+		{expr: "oktober ini", from: "2026-10-01", to: "2026-10-31"},
+		{expr: "januari depan", from: "2027-01-01", to: "2027-01-31"},
+		{expr: "last march", from: "2026-03-01", to: "2026-03-31"},
+		{expr: "feb 2024", from: "2024-02-01", to: "2024-02-29"},
+
+		// pergantian tahun
+		{expr: "bulan lalu", now: jan10, from: "2025-12-01", to: "2025-12-31"},
+		{expr: "desember", now: jan10, from: "2025-12-01", to: "2025-12-31"},
+		{expr: "januari lalu", now: jan10, from: "2025-01-01", to: "2025-01-31"},
+		{expr: "kuartal lalu", now: jan10, from: "2025-10-01", to: "2025-12-31"},
+		{expr: "minggu lalu", now: jan10, from: "2025-12-29", to: "2026-01-04"},
+		{expr: "3 bulan terakhir", now: jan10, from: "2025-10-11", to: "2026-01-10"},
+		{expr: "bulan depan", now: time.Date(2026, 12, 5, 0, 0, 0, 0, wib), from: "2027-01-01", to: "2027-01-31"},
+
+		// tahun
+		{expr: "2024", from: "2024-01-01", to: "2024-12-31"},
+		{expr: "tahun 2023", from: "2023-01-01", to: "2023-12-31"},
+
+		// hari dalam pekan: tanpa keterangan berarti yang terakhir tidak melewati hari ini
+		{expr: "jumat kemarin", from: "2026-10-16", to: "2026-10-16"},
+		{expr: "senin lalu", from: "2026-10-12", to: "2026-10-12"},
+		{expr: "hari jumat", from: "2026-10-16", to: "2026-10-16"},
+		{expr: "sabtu", from: "2026-10-17", to: "2026-10-17"},
+		{expr: "hari minggu", from: "2026-10-18", to: "2026-10-18"},
+		{expr: "hari minggu lalu", from: "2026-10-11", to: "2026-10-11"},
+		{expr: "jumat depan", from: "2026-10-23", to: "2026-10-23"},
+		{expr: "rabu ini", from: "2026-10-14", to: "2026-10-14"},
+		{expr: "last friday", from: "2026-10-16", to: "2026-10-16"},
+		{expr: "next monday", from: "2026-10-19", to: "2026-10-19"},
+		{expr: "jumat lalu", now: fri, from: "2026-10-09", to: "2026-10-09"},
+		{expr: "hari jumat", now: fri, from: "2026-10-16", to: "2026-10-16"},
+		{expr: "jumat depan", now: fri, from: "2026-10-23", to: "2026-10-23"},
+		{expr: "senin", now: fri, from: "2026-10-12", to: "2026-10-12"},
+
+		// awal/akhir periode
+		{expr: "awal bulan lalu", from: "2026-09-01", to: "2026-09-01"},
+		{expr: "akhir tahun", from: "2026-12-31", to: "2026-12-31"},
+		{expr: "end of last quarter", from: "2026-09-30", to: "2026-09-30"},
+
+		// rentang
+		{expr: "januari sampai maret", from: "2026-01-01", to: "2026-03-31"},
+		{expr: "dari 1 maret s/d 15 maret", from: "2026-03-01", to: "2026-03-15"},
+		{expr: "between 1 march and 15 march", from: "2026-03-01", to: "2026-03-15"},
+		{expr: "maret - mei 2025", from: "2025-03-01", to: "2025-05-31"},
+		{expr: "1 - 15 maret", from: "2026-03-01", to: "2026-03-15"},
+		{expr: "1 - 15 maret", now: time.Date(2026, 3, 10, 9, 0, 0, 0, wib), from: "2025-03-01", to: "2025-03-15"},
+		{expr: "januari sampai maret tahun lalu", from: "2025-01-01", to: "2025-03-31"},
+		{expr: "1 maret - 15 mei 2025", from: "2025-03-01", to: "2025-05-15"},
+		{expr: "sejak maret", from: "2026-03-01", to: "2026-10-18"},
+		{expr: "since last month", from: "2026-09-01", to: "2026-10-18"},
+	}
+	for _, tt := range tests {
+		ref := tt.now
+		if ref.IsZero() {
+			ref = now
+		}
+		r, err := Parse(tt.expr, ref)
+		if err != nil {
+			t.Errorf("Parse(%q, %s): %v", tt.expr, ref.Format(Layout), err)
+			continue
+		}
+		if !r.From.Equal(date(tt.from)) || !r.To.Equal(date(tt.to)) {
+			t.Errorf("Parse(%q, %s) = %s - %s, want %s - %s", tt.expr, ref.Format(Layout),
+				r.From.Format(Layout), r.To.Format(Layout), tt.from, tt.to)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{"", "kosong"},
+		{"   ", "kosong"},
+		{"kapan-kapan", "tidak dikenali"},
+		{"bulan", "tidak dikenali"},
+		{"minggu", "tidak dikenali"},
+		{"Q5", "tidak dikenali"},
+		{"0 hari terakhir", "lebih dari 0"},
+		{"31/02/2024", "tidak valid"},
+		{"2024-13-01", "tidak valid"},
+		{"13/2024", "tidak valid"},
+		{"maret sampai januari", "setelah akhir periode"},
+		{"1 - 31 februari 2025", "tidak valid"},
+		{"1 maret - 31/02/2025", "tidak valid"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr, now)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%q) error = %v, want error berisi %q", tt.expr, err, tt.err)
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		from, to, want string
+	}{
+		{"2026-10-18", "2026-10-18", "18 Oktober 2026"},
+		{"2026-09-01", "2026-09-30", "September 2026"},
+		{"2025-07-01", "2025-09-30", "Q3 2025"},
+		{"2026-01-01", "2026-06-30", "Semester 1 2026"},
+		{"2024-01-01", "2024-12-31", "2024"},
+		{"2026-01-01", "2026-03-31", "Q1 2026"},
+		{"2026-01-01", "2026-02-28", "Januari - Februari 2026"},
+		{"2025-11-01", "2026-02-28", "November 2025 - Februari 2026"},
+		{"2026-03-01", "2026-03-15", "1 - 15 Maret 2026"},
+		{"2026-09-19", "2026-10-18", "19 September - 18 Oktober 2026"},
+		{"2025-12-29", "2026-01-04", "29 Desember 2025 - 4 Januari 2026"},
+	}
+	for _, tt := range tests {
+		r := Range{From: date(tt.from), To: date(tt.to)}
+		if got := r.Label(); got != tt.want {
+			t.Errorf("Label(%s - %s) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+		// label harus bisa dibaca kembali oleh Parse
+		back, err := Parse(tt.want, now)
+		if err != nil || !back.From.Equal(r.From) || !back.To.Equal(r.To) {
+			t.Errorf("Parse(%q) = %s - %s, %v, want %s - %s", tt.want,
+				back.From.Format(Layout), back.To.Format(Layout), err, tt.from, tt.to)
+		}
+	}
+}
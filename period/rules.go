@@ -0,0 +1,407 @@
+package period
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var units = map[string]Unit{
+	"hari": Day, "day": Day, "days": Day,
+	"minggu": Week, "pekan": Week, "week": Week, "weeks": Week,
+	"bulan": Month, "month": Month, "months": Month,
+	"kuartal": Quarter, "triwulan": Quarter, "quarter": Quarter, "quarters": Quarter,
+	"semester": Half, "half": Half,
+	"tahun": Year, "year": Year, "years": Year,
+}
+
+// rels selisih periode dari kata ini/lalu/depan
+var rels = map[string]int{
+	"ini": 0, "this": 0, "current": 0, "sekarang": 0,
+	"lalu": -1, "kemarin": -1, "sebelumnya": -1, "last": -1, "previous": -1,
+	"depan": 1, "berikutnya": 1, "next": 1, "mendatang": 1,
+}
+
+var months = map[string]time.Month{
+	"januari": 1, "january": 1, "jan": 1,
+	"februari": 2, "pebruari": 2, "february": 2, "feb": 2,
+	"maret": 3, "march": 3, "mar": 3,
+	"april": 4, "apr": 4,
+	"mei": 5, "may": 5,
+	"juni": 6, "june": 6, "jun": 6,
+	"juli": 7, "july": 7, "jul": 7,
+	"agustus": 8, "august": 8, "agu": 8, "agt": 8, "aug": 8,
+	"september": 9, "sept": 9, "sep": 9,
+	"oktober": 10, "october": 10, "okt": 10, "oct": 10,
+	"november": 11, "nov": 11,
+	"desember": 12, "december": 12, "des": 12, "dec": 12,
+}
+
+// weekdays urutan hari dari Senin (0)
+var weekdays = map[string]int{
+	"senin": 0, "selasa": 1, "rabu": 2, "kamis": 3, "jumat": 4, "sabtu": 5, "minggu": 6, "ahad": 6,
+	"monday": 0, "tuesday": 1, "wednesday": 2, "thursday": 3, "friday": 4, "saturday": 5, "sunday": 6,
+}
+
+// dayWords ungkapan satu hari relatif terhadap hari ini
+var dayWords = map[string]int{
+	"hari ini": 0, "today": 0, "sekarang": 0, "now": 0,
+	"kemarin": -1, "yesterday": -1,
+	"kemarin lusa": -2, "day before yesterday": -2,
+	"besok": 1, "tomorrow": 1,
+	"lusa": 2, "day after tomorrow": 2,
+}
+
+// fillers kata yang tidak mengubah arti periode
+var fillers = map[string]bool{
+	"pada": true, "di": true, "selama": true, "dalam": true, "periode": true, "tanggal": true, "tgl": true, "yang": true,
+	"the": true, "of": true, "on": true, "in": true, "during": true, "for": true, "within": true,
+}
+
+var edges = map[string]bool{"awal": true, "akhir": true, "start": true, "beginning": true, "end": true}
+
+var numbers = map[string]int{
+	"satu": 1, "dua": 2, "tiga": 3, "empat": 4, "lima": 5, "enam": 6, "tujuh": 7, "delapan": 8, "sembilan": 9,
+	"sepuluh": 10, "sebelas": 11, "seratus": 100,
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+	"ten": 10, "eleven": 11, "twelve": 12, "fifteen": 15, "twenty": 20, "thirty": 30, "sixty": 60, "ninety": 90,
+}
+
+var ordinals = map[string]string{
+	"pertama": "1", "kedua": "2", "ketiga": "3", "keempat": "4",
+	"first": "1", "second": "2", "third": "3", "fourth": "4",
+	"1st": "1", "2nd": "2", "3rd": "3", "4th": "4",
+}
+
+// normalize huruf kecil, tanda baca dibuang, kata angka diubah menjadi digit
+// ("tiga puluh hari terakhir" -> "30 hari terakhir", "sebulan" -> "1 bulan")
+func normalize(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer(
+		"s/d", " sampai ", "s.d.", " sampai ", "sampai dengan", "sampai",
+		"–", " - ", "—", " - ", "'", "", "’", "",
+		",", " ", ".", " ", "?", " ", "!", " ", "(", " ", ")", " ", `"`, " ",
+	).Replace(s)
+
+	words := strings.Fields(s)
+	out := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		w := strings.TrimPrefix(words[i], "ke-")
+		next := ""
+		if i+1 < len(words) {
+			next = words[i+1]
+		}
+		switch {
+		case w == "" || fillers[w]:
+		case w == "ke" && (isDigits(next) || ordinals[next] != ""):
+		case (w == "a" || w == "an") && isUnit(next):
+			out = append(out, "1")
+		case strings.HasPrefix(w, "se") && isUnit(w[2:]):
+			out = append(out, "1", w[2:])
+		case ordinals[w] != "":
+			out = append(out, ordinals[w])
+		case numbers[w] > 0:
+			n, used := number(words[i:])
+			out = append(out, strconv.Itoa(n))
+			i += used - 1
+		default:
+			out = append(out, w)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// number membaca kata angka di awal words, mengembalikan nilai dan jumlah kata yang dipakai
+func number(words []string) (int, int) {
+	n, used := numbers[words[0]], 1
+	if n >= 10 || len(words) == 1 {
+		return n, used
+	}
+	switch words[1] {
+	case "belas":
+		return n + 10, 2
+	case "puluh":
+		n, used = n*10, 2
+		if len(words) > 2 && numbers[words[2]] > 0 && numbers[words[2]] < 10 {
+			n, used = n+numbers[words[2]], 3
+		}
+	}
+	return n, used
+}
+
+func isUnit(w string) bool {
+	_, ok := units[w]
+	return ok
+}
+
+func isDigits(w string) bool {
+	_, err := strconv.Atoi(w)
+	return err == nil
+}
+
+type rule struct {
+	re *regexp.Regexp
+	fn func(m []string, today time.Time) (Range, error)
+}
+
+var vocab = strings.NewReplacer(
+	"{unit}", `(hari|days?|minggu|pekan|weeks?|bulan|months?|kuartal|triwulan|quarters?|semester|half|tahun|years?)`,
+	"{rel}", `(ini|this|current|sekarang|lalu|kemarin|sebelumnya|last|previous|depan|berikutnya|next|mendatang)`,
+	"{month}", `(januari|january|jan|februari|pebruari|february|feb|maret|march|mar|april|apr|mei|may|juni|june|jun|juli|july|jul|agustus|august|agu|agt|aug|september|sept|sep|oktober|october|okt|oct|november|nov|desember|december|des|dec)`,
+	"{year}", `(\d{4}|tahun \d{4}|tahun ini|tahun lalu|tahun kemarin|tahun depan|this year|last year|next year)`,
+	"{weekday}", `(senin|selasa|rabu|kamis|jumat|sabtu|minggu|ahad|monday|tuesday|wednesday|thursday|friday|saturday|sunday)`,
+)
+
+func newRule(pattern string, fn func(m []string, today time.Time) (Range, error)) rule {
+	return rule{re: regexp.MustCompile(vocab.Replace(pattern)), fn: fn}
+}
+
+// rules dicoba berurutan, aturan yang mengembalikan errSkip dilewati
+var rules = []rule{
+	// bulan lalu, minggu ini, kuartal depan
+	newRule(`^{unit} {rel}$`, func(m []string, t time.Time) (Range, error) {
+		return Calendar(units[m[1]], t, rels[m[2]]), nil
+	}),
+	// last month, this week, next quarter
+	newRule(`^{rel} {unit}$`, func(m []string, t time.Time) (Range, error) {
+		return Calendar(units[m[2]], t, rels[m[1]]), nil
+	}),
+	// 3 bulan lalu, 2 weeks ago: periode kalender ke-n sebelumnya
+	newRule(`^(\d+) {unit} (lalu|ago|sebelumnya)$`, func(m []string, t time.Time) (Range, error) {
+		n, _ := strconv.Atoi(m[1])
+		return Calendar(units[m[2]], t, -n), nil
+	}),
+	// 2 minggu lagi
+	newRule(`^(\d+) {unit} lagi$`, func(m []string, t time.Time) (Range, error) {
+		n, _ := strconv.Atoi(m[1])
+		return Calendar(units[m[2]], t, n), nil
+	}),
+	// 7 hari terakhir, 3 bulan belakangan ini
+	newRule(`^(\d+) {unit} (?:terakhir|belakangan)(?: ini)?$`, func(m []string, t time.Time) (Range, error) {
+		return rolling(m[1], m[2], t, 1)
+	}),
+	// last 30 days, past 3 months
+	newRule(`^(?:last|past) (\d+) {unit}$`, func(m []string, t time.Time) (Range, error) {
+		return rolling(m[1], m[2], t, 1)
+	}),
+	// past week
+	newRule(`^past {unit}$`, func(m []string, t time.Time) (Range, error) {
+		return rolling("1", m[1], t, 1)
+	}),
+	// 7 hari ke depan, next 14 days
+	newRule(`^(\d+) {unit} (?:ke depan|kedepan|mendatang)$`, func(m []string, t time.Time) (Range, error) {
+		return rolling(m[1], m[2], t, -1)
+	}),
+	newRule(`^next (\d+) {unit}$`, func(m []string, t time.Time) (Range, error) {
+		return rolling(m[1], m[2], t, -1)
+	}),
+	// tahun berjalan, month to date
+	newRule(`^{unit} (?:berjalan|to date)$`, func(m []string, t time.Time) (Range, error) {
+		return ToDate(units[m[1]], t), nil
+	}),
+	// ytd, mtd
+	newRule(`^(w|m|q|y)td$`, func(m []string, t time.Time) (Range, error) {
+		return ToDate(map[string]Unit{"w": Week, "m": Month, "q": Quarter, "y": Year}[m[1]], t), nil
+	}),
+	// Q3 2024, kuartal 3 tahun lalu, third quarter of last year
+	newRule(`^(?:q|kuartal|triwulan|quarter|kw) ?([1-4])(?: {year})?$`, func(m []string, t time.Time) (Range, error) {
+		return part(Quarter, m[1], m[2], t)
+	}),
+	newRule(`^([1-4]) (?:quarter|kuartal|triwulan)(?: {year})?$`, func(m []string, t time.Time) (Range, error) {
+		return part(Quarter, m[1], m[2], t)
+	}),
+	// semester 1, H2 2024, first half of this year
+	newRule(`^(?:semester|h|half|paruh) ?([12])(?: {year})?$`, func(m []string, t time.Time) (Range, error) {
+		return part(Half, m[1], m[2], t)
+	}),
+	newRule(`^([12]) (?:half|semester)(?: {year})?$`, func(m []string, t time.Time) (Range, error) {
+		return part(Half, m[1], m[2], t)
+	}),
+	// 2024-03-05, 05/03/2024
+	newRule(`^(\d{4})-(\d{1,2})-(\d{1,2})$`, func(m []string, t time.Time) (Range, error) {
+		return day(m[0], m[1], m[2], m[3], t)
+	}),
+	newRule(`^(\d{1,2})[/-](\d{1,2})[/-](\d{4})$`, func(m []string, t time.Time) (Range, error) {
+		return day(m[0], m[3], m[2], m[1], t)
+	}),
+	// 5 maret 2024, 17 agustus tahun lalu
+	newRule(`^(\d{1,2}) {month}(?: {year})?$`, func(m []string, t time.Time) (Range, error) {
+		return day(m[0], m[3], strconv.Itoa(int(months[m[2]])), m[1], t)
+	}),
+	// march 5th 2024
+	newRule(`^{month} (\d{1,2})(?:st|nd|rd|th)?(?: {year})?$`, func(m []string, t time.Time) (Range, error) {
+		return day(m[0], m[3], strconv.Itoa(int(months[m[1]])), m[2], t)
+	}),
+	// 03/2024
+	newRule(`^(\d{1,2})[/-](\d{4})$`, func(m []string, t time.Time) (Range, error) {
+		mon, _ := strconv.Atoi(m[1])
+		if mon < 1 || mon > 12 {
+			return Range{}, fmt.Errorf("bulan %q tidak valid", m[0])
+		}
+		y, _ := strconv.Atoi(m[2])
+		return month(time.Month(mon), y, t), nil
+	}),
+	// maret, bulan maret 2024, maret tahun lalu, maret lalu
+	newRule(`^(?:bulan )?{month}(?: (.+))?$`, func(m []string, t time.Time) (Range, error) {
+		return namedMonth(months[m[1]], m[2], t)
+	}),
+	// last march, this december
+	newRule(`^{rel} {month}$`, func(m []string, t time.Time) (Range, error) {
+		return namedMonth(months[m[2]], m[1], t)
+	}),
+	// 2024, tahun 2024
+	newRule(`^(?:tahun )?(\d{4})$`, func(m []string, t time.Time) (Range, error) {
+		y, _ := strconv.Atoi(m[1])
+		return Calendar(Year, time.Date(y, 1, 1, 0, 0, 0, 0, t.Location()), 0), nil
+	}),
+	// senin lalu, hari minggu, jumat depan
+	newRule(`^(hari )?{weekday}(?: {rel})?$`, func(m []string, t time.Time) (Range, error) {
+		// "minggu" tanpa "hari" berarti pekan, bukan hari Minggu
+		if m[2] == "minggu" && m[1] == "" {
+			return Range{}, errSkip
+		}
+		return weekday(m[2], m[3], t), nil
+	}),
+	// last friday, next monday
+	newRule(`^{rel} {weekday}$`, func(m []string, t time.Time) (Range, error) {
+		return weekday(m[2], m[1], t), nil
+	}),
+}
+
+// rolling dir 1 untuk n satuan terakhir, -1 untuk n satuan ke depan
+func rolling(num, unit string, t time.Time, dir int) (Range, error) {
+	n, _ := strconv.Atoi(num)
+	if n < 1 {
+		return Range{}, fmt.Errorf("jumlah periode harus lebih dari 0")
+	}
+	return Rolling(units[unit], t, n*dir), nil
+}
+
+// year membaca tahun dari "2024", "tahun 2024", "tahun lalu", "next year"; kosong berarti tidak disebut
+func year(s string, t time.Time) (int, bool) {
+	w, named := strings.CutPrefix(s, "tahun ")
+	if y, err := strconv.Atoi(w); err == nil {
+		return y, true
+	}
+	if !named {
+		w, named = strings.CutSuffix(s, " year")
+	}
+	if n, ok := rels[w]; ok && named {
+		return t.Year() + n, true
+	}
+	return 0, false
+}
+
+// part kuartal atau semester ke-idx, tanpa tahun berarti yang terakhir sudah dimulai
+func part(u Unit, idx, yr string, t time.Time) (Range, error) {
+	i, _ := strconv.Atoi(idx)
+	size := 3
+	if u == Half {
+		size = 6
+	}
+	y, ok := year(yr, t)
+	if !ok {
+		if yr != "" {
+			return Range{}, errSkip
+		}
+		y = t.Year()
+		if time.Month((i-1)*size+1) > t.Month() {
+			y--
+		}
+	}
+	return Calendar(u, time.Date(y, time.Month((i-1)*size+1), 1, 0, 0, 0, 0, t.Location()), 0), nil
+}
+
+func month(m time.Month, y int, t time.Time) Range {
+	return Calendar(Month, time.Date(y, m, 1, 0, 0, 0, 0, t.Location()), 0)
+}
+
+// namedMonth bulan dengan keterangan tahun atau ini/lalu/depan; tanpa keterangan berarti
+// bulan tersebut yang terakhir sudah dimulai
+func namedMonth(m time.Month, rest string, t time.Time) (Range, error) {
+	cur := t.Month()
+	if y, ok := year(rest, t); ok {
+		return month(m, y, t), nil
+	}
+	n, ok := rels[rest]
+	switch {
+	case rest == "":
+		if m > cur {
+			return month(m, t.Year()-1, t), nil
+		}
+		return month(m, t.Year(), t), nil
+	case !ok:
+		return Range{}, errSkip
+	case n < 0:
+		// maret lalu: maret terakhir sebelum bulan ini
+		if m >= cur {
+			return month(m, t.Year()-1, t), nil
+		}
+	case n > 0:
+		if m <= cur {
+			return month(m, t.Year()+1, t), nil
+		}
+	}
+	return month(m, t.Year(), t), nil
+}
+
+// day satu tanggal, tahun kosong berarti tanggal tersebut yang terakhir sudah lewat
+func day(expr, yr, mon, d string, t time.Time) (Range, error) {
+	mi, _ := strconv.Atoi(mon)
+	di, _ := strconv.Atoi(d)
+	y, ok := year(yr, t)
+	if !ok && yr != "" {
+		return Range{}, errSkip
+	}
+	if !ok {
+		y = t.Year()
+		if time.Date(y, time.Month(mi), di, 0, 0, 0, 0, t.Location()).After(t) {
+			y--
+		}
+	}
+	date := time.Date(y, time.Month(mi), di, 0, 0, 0, 0, t.Location())
+	if mi < 1 || mi > 12 || date.Day() != di {
+		return Range{}, fmt.Errorf("tanggal %q tidak valid", expr)
+	}
+	return Range{From: date, To: date}, nil
+}
+
+// edge hari pertama atau terakhir periode: awal bulan lalu, akhir tahun, end of last quarter
+func edge(s string, t time.Time) (Range, error) {
+	w, rest, _ := strings.Cut(s, " ")
+	if !edges[w] || rest == "" {
+		return Range{}, errSkip
+	}
+	r, err := single(rest, t)
+	if errors.Is(err, errSkip) {
+		r, err = single(rest+" ini", t)
+	}
+	if err != nil {
+		return Range{}, err
+	}
+	d := r.From
+	if w == "akhir" || w == "end" {
+		d = r.To
+	}
+	return Range{From: d, To: d}, nil
+}
+
+// weekday hari tertentu yang terakhir tidak melewati hari ini. rel lalu/kemarin/last berarti yang terakhir
+// sebelum hari ini, depan/next yang pertama setelah hari ini, ini/this hari tersebut di pekan ini
+func weekday(name, rel string, t time.Time) Range {
+	back := (weekdayIndex(t) - weekdays[name] + 7) % 7
+	d := t.AddDate(0, 0, -back)
+	switch n := rels[rel]; {
+	case rel == "":
+	case n == 0:
+		d = Calendar(Week, t, 0).From.AddDate(0, 0, weekdays[name])
+	case n < 0 && back == 0:
+		d = d.AddDate(0, 0, -7)
+	case n > 0:
+		d = d.AddDate(0, 0, 7)
+	}
+	return Range{From: d, To: d}
+}
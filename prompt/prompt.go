@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/MaulanaR/zai/tenant"
 )
 
 // - dashboards/daily_sales: Daily sales data queries
@@ -130,257 +132,61 @@ Respond only with the JSON decision object:
 {"params": {"param_key":"param_value"}}`)
 }
 
-func SystemMSG() string {
-	output := `<response_rules>
-	<!-- Strict response format rules -->
-	<api_request>
-		When data is needed, respond ONLY with a JSON object containing endpoint and params.
-		No explanation, no additional text.
-
-		Format:
-		{"input" : false, "endpoint": "endpoint_name", "params": {"param_key": "param_value" // Include all required parameters} }
-
-		If no data already provided:
-		{"input" : false, "endpoint": "null", "response" : "" //other response set null}
-	</api_request>
-
-	<data_input>
-		When handling data input, respond ONLY with a JSON object.
-		No explanation, no additional text.
-
-		{"input" : true, "endpoint" : "endpoint", "type": "type"}
-
-		Format for contact (customer, supplier, employee):
-		set "type": "kontak", "endpoint" : "contacts"
-		
-		Format for product:
-		"type": "products" , "endpoint" : "products"
-
-		Formet fot sales:
-		"type" : "sales_invoices", "endpoint" : "sales_invoices"
-
-		set params same as listed in data_input validation_rules
-	</data_input>
-</response_rules>
-
-<data_rules>
-	1. Mapping data yang ada dengan avaiable fields yang telah ditentukan
-	<api_endpoints>
-		<available_endpoints>
-			- contacts: Customer, Vendor, Employee queries
-			- sales_invoices: Sales Invoice queries
-			- products: Product queries
-			- purchases_invoices: Purchase Invoice queries
-			- dashboards/profit_loss_simple: Profit Loss queries
-			- dashboards/balance_sheet_simple: Balance Sheet queries
-		</available_endpoints>
-
-		<endpoint_params>
-			<default_params>
-				{"per_page": "10"}
-				if endpoint is contacts, then {"per_page": "50"}
-			</default_params>
-
-			<available_fields>
-				<sales_invoices>
-					- customer.name
-					- payment_status (enum: open, paid)
-					- date
-					- time
-					- number
-					- description
-					- currency.name
-					- subtotal
-					- total_discount
-					- subtotal_before_tax
-					- total_tax
-					- total_cash_amount
-					- total_amount
-					- total_payment
-					- line_items (product information)
-				</sales_invoices>
-
-				<purchases_invoices>
-					- description
-					- date
-					- time
-					- number
-					- note
-					- total_amount
-				</purchases_invoices>
-
-				<products>
-					- code
-					- name
-					- description
-					- category.name
-					- catalog.name
-					- quantity.on_hand
-					- quantity.on_order
-					- quantity.on_hold
-					- unit_price_gross
-					- unit_price
-					- unit_cogs
-				</products>
-
-				<contacts>
-					- name
-					- note
-					- national_id_number
-					- tax_id_number
-					- is_customer
-					- is_supplier
-					- is_employee
-					- is_salesman
-					- is_active
-					- customer_category.name
-				</contacts>
-			</available_fields>
-
-			<special_params>
-				<sales_invoices_query>
-					if user need to show the products of sales invoices
-					{"includes[line_items]": "true"}
-				</sales_invoices_query>
-
-				<date_query>
-					Format: YYYY-MM-DD
-					Operators: date[$gte], date[$lte], date[$eq]
-				</date_query>
-			</special_params>
-		</endpoint_params>
-	</api_endpoints>
-
-	<data_input>
-		<allowed_types>
-			- contacts
-			- products
-		</allowed_types>
-
-		<validation_rules>
-			- do not edit/add anything to fields already filled by the user
-			<contacts>
-				Required fields:
-				- name (string) as "name"
-				- phone (string) as "phone"
-				- email (string, valid email format) as "email"
-			</contacts>
-
-			<products>
-				Required fields:
-				- name (string)
-				- price (number)
-				- category (string)
-			</products>
-		</validation_rules>
-	</data_input>
-</data_rules>
-
-<processing_rules>
-	1. MUST respond with clean JSON only
-	2. NO explanatory text before or after JSON
-	3. Check context before requesting data
-	4. Include default params when needed
-	5. Validate all required fields for input
-	6. Use proper date format YYYY-MM-DD
-</processing_rules>
+// RouterMSG system prompt untuk memilih tool Zahir yang dipanggil, now adalah waktu di zona waktu user
+func RouterMSG(now time.Time) string {
+	output := `<routing_rules>
+	1. Check the data already provided in the conversation first. If it is enough to answer, do NOT call any tool.
+	2. If data is missing, call the tools that return the needed data. You may call several tools, one after another, and combine their results.
+	3. When the user wants to add new data (contact, customer, supplier, employee, product, sales invoice, purchase invoice), call the matching create tool.
+	4. Do not edit/add anything to fields already filled by the user.
+	5. Default per_page is "10", for contacts use "50".
+	6. Use date format YYYY-MM-DD for every date argument. For relative periods (kemarin, minggu ini, bulan lalu, Q3 tahun lalu, last 30 days) call resolve_period first and use its from/to; never compute these dates yourself.
+	7. For totals, counts, averages, min/max, rankings or anything per customer/product/month, call an aggregate tool and only explain its numbers. Never add up rows yourself.
+	8. Pass customer, contact and product names exactly as the user wrote them (e.g. "pak budi"); the server matches them to Zahir data and asks the user when several records match.
+	9. When the user wants to change or remove an existing contact or product, call the matching update or delete tool with the current code or name as target. Send only the fields that change; "deactivate" means update with is_active false, not delete.
+	10. If the request misses information you cannot assume (e.g. invoices or sales without any period), call ask_user with a short question and choices instead of guessing.
+</routing_rules>
 
 <today_date>
-` + time.Now().Format("2006-01-02") + `
+` + now.Format("2006-01-02 (Monday) MST") + `
 </today_date>`
 
 	output = strings.NewReplacer("\n", " ", "\t", " ").Replace(output)
 	return fmt.Sprint(output)
 }
 
-func GenerateResRule() string {
-	output := `<today_date>
-	` + time.Now().Format("2006-01-02") + `
-	</today_date>
-	<response_rules>
+// AgentMSG system prompt untuk agent: aturan pemilihan tool dan aturan jawaban akhir sesuai pengaturan tenant
+func AgentMSG(now time.Time, s tenant.Settings) string {
+	return RouterMSG(now) + " " + GenerateResRule(s)
+}
+
+// GenerateResRule aturan jawaban akhir, bahasa dan mata uang mengikuti pengaturan tenant
+func GenerateResRule(s tenant.Settings) string {
+	output := `<response_rules>
 	- Jawab pertanyaan pengguna secara natural berdasarkan data yang diberikan
 	- Jika perlu, Tambahkan bantuan/sugesti terkait data yang diberikan, Contoh : tampilkan berdasarkan spesifik data tertentu, dan lainnya agar lebih ringkas
 	- Hanya tampilkan data yang bisa dibaca manusia, jangan tampilkan data yang nilainya null/NULL
 	- Hanya sertakan informasi yang relevan dan jangan menjawab jika pertanyaan tidak terkait dengan data yang ditentukan atau tidak tentang Zahir.
-	- Format semua harga dalam mata uang Rupiah.
-	- Respon dalam BAHASA INDONESIA
-	- Jangan response dalam chart/grafik jika user tidak menginginkan
-	- JIKA pengguna ingin disajikan dalam bentuk chart/grafik, maka gunakan HIGHCHART DALAM HTML & JS
-	- Default sajikan sebagai tabel html dengan class milik bootstrap
-	</response_rules>`
-
-	output = strings.NewReplacer("\n", " ", "\t", " ").Replace(output)
-	return fmt.Sprint(output)
-}
-
-func GenerateForm() string {
-	output := `<today_date>
-	` + time.Now().Format("2006-01-02") + `
-	</today_date>
-	<response_rules>
-	- Tampilkan form dalam html dan CSS bootstrap
-	- Tampilkan keseluruhan kode,Jangan tambahkan penjelasan kode
-	- Buat field sesuai dengan data input yang diberikan
-	- Tambahkan tombol untuk submit, yang isinya fungsi javascript yang mengirimkan isian data dari form input ke input id="messageInput", dan jalankan fungsi click pada tombol dengan id="sendButton"
-	- Pastikan form input yang dibuat sesuai dengan avaiable_fields
+	- Nominal tanpa currency.name memakai mata uang ` + s.Currency + `; nominal dengan currency.name lain tampilkan dalam mata uangnya sendiri, jangan dijumlahkan dengan mata uang lain.
+	- Di block table kirim angka dan tanggal mentah (jangan diformat) dan isi "types" per kolom, server yang memformat sesuai pengaturan tenant.
+	- Respon dalam ` + strings.ToUpper(s.LanguageName()) + `
+	- Jangan response dalam chart jika user tidak menginginkan
+	- Default sajikan data sebagai block table
+	- JANGAN gunakan HTML, markdown atau javascript
 	</response_rules>
-	
-	<available_fields>
-	<sales_invoices>
-		- customer.name
-		- payment_status (enum: open, paid)
-		- date
-		- time
-		- number
-		- description
-		- currency.name
-		- subtotal
-		- total_discount
-		- subtotal_before_tax
-		- total_tax
-		- total_cash_amount
-		- total_amount
-		- total_payment
-		- line_items (product information)
-	</sales_invoices>
-
-	<purchases_invoices>
-		- description
-		- date
-		- time
-		- number
-		- note
-		- total_amount
-	</purchases_invoices>
-
-	<products>
-		- code
-		- name
-		- description
-		- category.name
-		- catalog.name
-		- quantity.on_hand
-		- quantity.on_order
-		- quantity.on_hold
-		- unit_price_gross
-		- unit_price
-		- unit_cogs
-	</products>
-
-	<contacts>
-		- name
-		- note
-		- national_id_number
-		- tax_id_number
-		- is_customer
-		- is_supplier
-		- is_employee
-		- is_salesman
-		- is_active
-		- customer_category.name
-	</contacts>
-</available_fields>`
+	` + blockFormat
 
 	output = strings.NewReplacer("\n", " ", "\t", " ").Replace(output)
 	return fmt.Sprint(output)
 }
+
+// blockFormat format JSON jawaban, divalidasi oleh paket answer
+const blockFormat = `<response_format>
+	Respond only with a JSON object {"blocks": [...]}. Available blocks:
+	{"type":"text","text":"plain text"}
+	{"type":"table","title":"optional","table":{"columns":["Tanggal","Customer","Mata Uang","Total"],"types":["date","text","currency","money"],"rows":[["2024-01-25","Budi","IDR",150000]]}}
+	{"type":"chart","title":"optional","chart":{"type":"line|spline|area|column|bar|pie","categories":["Jan","Feb"],"series":[{"name":"Penjualan","data":[100,200]}]}}
+	{"type":"form","title":"optional","form":{"fields":[{"name":"field_name","label":"Label","type":"text|number|email|date|textarea|select|checkbox","required":true,"options":["only for select"]}],"submit":"Simpan"}}
+	Table types are optional, one per column: text, number, money, currency (ISO code of the row, used by its money cells), date (YYYY-MM-DD); "currency" on the table sets the money currency when there is no currency column.
+	Every table row must have the same number of cells as columns. Every chart series must have one number per category.
+	</response_format>`
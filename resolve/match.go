@@ -0,0 +1,158 @@
+package resolve
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// ignored sapaan dan bentuk badan usaha yang tidak ikut dicocokkan
+var ignored = map[string]bool{
+	"pak": true, "bapak": true, "bpk": true, "bu": true, "ibu": true, "mas": true, "mbak": true, "mba": true,
+	"kak": true, "bang": true, "sdr": true, "sdri": true, "tn": true, "ny": true, "nn": true, "hj": true,
+	"pt": true, "cv": true, "ud": true, "pd": true, "tbk": true,
+}
+
+// ejaan lama dan variasi penulisan yang bunyinya sama
+var phoneticReplacer = strings.NewReplacer(
+	"dj", "j", "tj", "c", "sj", "sy", "oe", "u", "ch", "h", "kh", "h",
+	"ph", "f", "v", "f", "q", "k", "x", "ks", "z", "s",
+)
+
+// tokens kata-kata nama dalam huruf kecil tanpa tanda baca, sapaan dan badan usaha dibuang
+func tokens(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	res := []string{}
+	for _, w := range words {
+		if !ignored[w] {
+			res = append(res, w)
+		}
+	}
+	if len(res) == 0 {
+		return words
+	}
+	return res
+}
+
+// phonetic kunci bunyi kata: ejaan lama diseragamkan, h di tengah kata dibuang,
+// huruf ganda digabung dan o disamakan dengan u (Mohammad, Muhamad, Muhammad)
+func phonetic(w string) string {
+	w = phoneticReplacer.Replace(w)
+	b := strings.Builder{}
+	var last rune
+	for i, r := range w {
+		if r == 'h' && i > 0 {
+			continue
+		}
+		if r == 'o' {
+			r = 'u'
+		}
+		if r != last {
+			b.WriteRune(r)
+		}
+		last = r
+	}
+	return b.String()
+}
+
+// score kemiripan query dengan satu data antara 0 dan 1. Nama atau kode yang sama persis bernilai 1,
+// selain itu rata-rata kemiripan setiap kata query ditambah porsi kata nama data yang tercakup
+func score(query string, q []string, it indexed) float64 {
+	query = strings.TrimSpace(query)
+	if strings.EqualFold(query, it.name) || (query != "" && strings.EqualFold(query, str(it.record, "code"))) {
+		return 1
+	}
+	if len(q) == 0 || len(it.tokens) == 0 {
+		return 0
+	}
+	if strings.Join(q, " ") == strings.Join(it.tokens, " ") {
+		return 1
+	}
+
+	total := 0.0
+	covered := map[int]bool{}
+	for _, qt := range q {
+		best, bestIdx := 0.0, -1
+		for i, nt := range it.tokens {
+			if s := similarity(qt, nt); s > best {
+				best, bestIdx = s, i
+			}
+		}
+		total += best
+		if best >= 0.85 {
+			covered[bestIdx] = true
+		}
+	}
+	return 0.85*total/float64(len(q)) + 0.15*float64(len(covered))/float64(len(it.tokens))
+}
+
+// similarity kemiripan dua kata: Jaro-Winkler, bunyi yang sama, atau awalan kata (minimal 3 huruf)
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	s := jaroWinkler(a, b)
+	if phonetic(a) == phonetic(b) && s < 0.95 {
+		s = 0.95
+	}
+	if len(a) >= 3 && strings.HasPrefix(b, a) && s < 0.9 {
+		s = 0.9
+	}
+	return s
+}
+
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	ma, mb := make([]bool, len(ra)), make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !mb[j] && ra[i] == rb[j] {
+				ma[i], mb[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !ma[i] {
+			continue
+		}
+		for !mb[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+func round(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
@@ -0,0 +1,191 @@
+package resolve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaulanaR/zai/zahir"
+)
+
+// testContacts data kontak yang dilayani server Zahir palsu
+var testContacts = []map[string]any{
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b401", "name": "Budi Santoso", "is_customer": true},
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b402", "name": "Djoko Susilo", "is_supplier": true},
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b403", "name": "Muhammad Rizki", "is_customer": true},
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b404", "name": "CV Sinar Jaya", "is_supplier": true},
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b405", "name": "Sinar Mas Abadi", "is_customer": true},
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b406", "name": "Agus Salim", "is_customer": true},
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b407", "name": "Agus Saleh", "is_customer": true},
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b408", "name": "Siti Aminah", "is_employee": true},
+}
+
+var testProducts = []map[string]any{
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b501", "code": "IDM-GRG", "name": "Indomie Goreng"},
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b502", "code": "IDM-SOTO", "name": "Indomie Soto"},
+	{"id": "6513270e-269e-4d37-b2a7-4de452e6b503", "code": "KPI-01", "name": "Kopi Kapal Api"},
+}
+
+func newTestClient(t *testing.T) *zahir.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data := testContacts
+		if r.URL.Path == "/products" {
+			data = testProducts
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": data, "count": len(data), "page": 1, "total_pages": 1})
+	}))
+	t.Cleanup(srv.Close)
+	return zahir.NewClient(srv.URL, "tok", "s", srv.Client())
+}
+
+func TestResolveOne(t *testing.T) {
+	c := newTestClient(t)
+	r := New(time.Minute)
+	tests := []struct {
+		name  string
+		kind  Kind
+		query string
+		want  string   // nama data yang dipilih
+		ambig []string // kandidat jika ambigu, nil jika harus pasti
+	}{
+		{"sama persis", Customer, "Budi Santoso", "Budi Santoso", nil},
+		{"kode produk", Product, "idm-soto", "Indomie Soto", nil},
+		{"sapaan dibuang", Customer, "pak budi", "Budi Santoso", nil},
+		{"sapaan bu", Employee, "bu siti", "Siti Aminah", nil},
+		{"ejaan lama dj", Supplier, "joko susilo", "Djoko Susilo", nil},
+		{"ejaan lama di data", Supplier, "Djoko", "Djoko Susilo", nil},
+		{"mohammad muhammad", Customer, "mohammad rizki", "Muhammad Rizki", nil},
+		{"muhamad satu m", Customer, "muhamad rizky", "Muhammad Rizki", nil},
+		{"badan usaha pt vs cv", Supplier, "PT Sinar", "CV Sinar Jaya", nil},
+		{"badan usaha tanpa awalan", Supplier, "sinar jaya", "CV Sinar Jaya", nil},
+		{"salah ketik satu huruf", Customer, "budi santosa", "Budi Santoso", nil},
+		{"salah ketik produk", Product, "indomie gorng", "Indomie Goreng", nil},
+		{"peran membatasi kandidat", Customer, "sinar", "Sinar Mas Abadi", nil},
+		{"salah ketik di antara nama mirip", Customer, "agus salm", "Agus Salim", nil},
+		{"skor sama ambigu", Customer, "agus sal", "", []string{"Agus Salim", "Agus Saleh"}},
+		{"skor berdekatan dalam Margin ambigu", Customer, "agus salih", "", []string{"Agus Salim", "Agus Saleh"}},
+		{"nama depan saja ambigu", Customer, "agus", "", []string{"Agus Salim", "Agus Saleh"}},
+		{"dua produk ambigu", Product, "indomie", "", []string{"Indomie Goreng", "Indomie Soto"}},
+	}
+	for _, tt := range tests {
+		got, err := r.One(context.Background(), c, tt.kind, tt.query)
+		if tt.ambig == nil {
+			if err != nil {
+				t.Errorf("%s: One(%s, %q) error = %v, want %s", tt.name, tt.kind, tt.query, err, tt.want)
+			} else if got.Name != tt.want {
+				t.Errorf("%s: One(%s, %q) = %s (%.3f), want %s", tt.name, tt.kind, tt.query, got.Name, got.Score, tt.want)
+			}
+			continue
+		}
+		var rerr *Error
+		if !errors.As(err, &rerr) {
+			t.Errorf("%s: One(%s, %q) = %s, %v, want kandidat ambigu %v", tt.name, tt.kind, tt.query, got.Name, err, tt.ambig)
+			continue
+		}
+		names := strings.Join(rerr.Names(), ", ")
+		for _, n := range tt.ambig {
+			if !strings.Contains(names, n) {
+				t.Errorf("%s: kandidat %q = %s, want memuat %s", tt.name, tt.query, names, n)
+			}
+		}
+		if c := rerr.Candidates; len(c) > 1 && c[0].Score-c[1].Score >= Margin {
+			t.Errorf("%s: selisih skor %.3f tidak di bawah Margin", tt.name, c[0].Score-c[1].Score)
+		}
+	}
+}
+
+func TestResolveNotFound(t *testing.T) {
+	c := newTestClient(t)
+	r := New(time.Minute)
+	for _, q := range []string{"zulkarnain", "xyz", "bu ratna"} {
+		_, err := r.One(context.Background(), c, Contact, q)
+		var rerr *Error
+		if !errors.As(err, &rerr) || len(rerr.Candidates) != 0 {
+			t.Errorf("One(%q) error = %v, want tidak ditemukan", q, err)
+		}
+	}
+	// kandidat di bawah MinScore tidak dikembalikan
+	cands, err := r.Resolve(context.Background(), c, Contact, "budiman")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cand := range cands {
+		if cand.Score < MinScore {
+			t.Errorf("Resolve(budiman) mengembalikan %s dengan skor %.3f < MinScore", cand.Name, cand.Score)
+		}
+	}
+}
+
+func TestPick(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []float64
+		ok     bool
+	}{
+		{"kosong", nil, false},
+		{"satu kandidat", []float64{0.8}, true},
+		{"sama persis mengalahkan yang lain", []float64{1, 0.98}, true},
+		{"dua sama persis", []float64{1, 1}, false},
+		{"selisih tepat Margin", []float64{0.9, 0.9 - Margin}, true},
+		{"selisih di bawah Margin", []float64{0.9, 0.87}, false},
+		{"selisih jauh", []float64{0.95, 0.76}, true},
+	}
+	for _, tt := range tests {
+		cands := make([]Candidate, len(tt.scores))
+		for i, s := range tt.scores {
+			cands[i] = Candidate{Name: string(rune('a' + i)), Score: s}
+		}
+		got, ok := Pick(cands)
+		if ok != tt.ok || (ok && got.Name != "a") {
+			t.Errorf("%s: Pick(%v) = %q, %v, want %v", tt.name, tt.scores, got.Name, ok, tt.ok)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"budi", "budi", 1, 1},
+		{"djoko", "joko", 0.95, 1},
+		{"mohammad", "muhammad", 0.95, 1},
+		{"muhamad", "muhammad", 0.95, 1},
+		{"tjipto", "cipto", 0.95, 1},
+		{"soekarno", "sukarno", 0.95, 1},
+		{"santosa", "santoso", 0.85, 0.95},
+		{"gorng", "goreng", 0.85, 1},
+		{"ind", "indomie", 0.9, 1},
+		{"budi", "siti", 0, 0.75},
+	}
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("similarity(%q, %q) = %.3f, want antara %.2f dan %.2f", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Pak Budi", "budi"},
+		{"Bpk. H. Budi", "h budi"},
+		{"PT Sinar Jaya, Tbk", "sinar jaya"},
+		{"CV. Sinar Jaya", "sinar jaya"},
+		{"Ibu", "ibu"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tokens(tt.in), " "); got != tt.want {
+			t.Errorf("tokens(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
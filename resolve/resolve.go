@@ -0,0 +1,228 @@
+// Package resolve mencocokkan nama yang diketik user ("pak budi", "indomie gorng", "PT Sinar")
+// dengan kontak dan produk di Zahir. Data diambil sekali per slug lalu disimpan di cache,
+// pencocokan memakai kemiripan ejaan dan fonetik yang disesuaikan dengan nama Indonesia.
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaulanaR/zai/aggregate"
+	"github.com/MaulanaR/zai/zahir"
+)
+
+// Kind jenis data yang dicari
+type Kind string
+
+const (
+	Contact  Kind = "kontak"
+	Customer Kind = "customer"
+	Supplier Kind = "supplier"
+	Employee Kind = "employee"
+	Product  Kind = "produk"
+)
+
+const (
+	// MinScore skor minimal kandidat
+	MinScore = 0.75
+	// Margin selisih skor minimal kandidat teratas dengan kandidat kedua agar dianggap pasti
+	Margin = 0.05
+	// MaxCandidates jumlah kandidat yang dikembalikan
+	MaxCandidates = 5
+)
+
+// Candidate data yang cocok dengan query, diurutkan dari skor tertinggi
+type Candidate struct {
+	ID     string           `json:"id"`
+	Name   string           `json:"name"`
+	Code   string           `json:"code,omitempty"`
+	Score  float64          `json:"score"`
+	Record aggregate.Record `json:"-"`
+}
+
+// Error nama tidak ditemukan atau cocok dengan beberapa data yang skornya berdekatan
+type Error struct {
+	Kind       Kind
+	Query      string
+	Candidates []Candidate
+}
+
+func (e *Error) Error() string {
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("%s %q tidak ditemukan", e.Kind, e.Query)
+	}
+	return fmt.Sprintf("%s %q cocok dengan lebih dari satu data: %s", e.Kind, e.Query, strings.Join(e.Names(), ", "))
+}
+
+// Names nama setiap kandidat
+func (e *Error) Names() []string {
+	names := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Resolver cache kontak dan produk per slug
+type Resolver struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	cache map[string]*entry
+}
+
+type entry struct {
+	mu        sync.Mutex
+	fetchedAt time.Time
+	contacts  []indexed
+	products  []indexed
+}
+
+// indexed record beserta token nama yang sudah dinormalisasi
+type indexed struct {
+	record aggregate.Record
+	name   string
+	tokens []string
+}
+
+// New membuat resolver, data per slug diambil ulang setelah ttl
+func New(ttl time.Duration) *Resolver {
+	return &Resolver{ttl: ttl, cache: map[string]*entry{}}
+}
+
+// Invalidate membuang cache slug, dipanggil setelah kontak/produk diubah
+func (r *Resolver) Invalidate(slug string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cache, slug)
+}
+
+// Resolve mencari kandidat untuk query, diurutkan dari skor tertinggi
+func (r *Resolver) Resolve(ctx context.Context, c *zahir.Client, kind Kind, query string) ([]Candidate, error) {
+	e, err := r.load(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	q := tokens(query)
+	items := e.contacts
+	if kind == Product {
+		items = e.products
+	}
+
+	res := []Candidate{}
+	for _, it := range items {
+		if !hasRole(it.record, kind) {
+			continue
+		}
+		s := score(query, q, it)
+		if s < MinScore {
+			continue
+		}
+		res = append(res, Candidate{
+			ID:     str(it.record, "id"),
+			Name:   it.name,
+			Code:   str(it.record, "code"),
+			Score:  round(s),
+			Record: it.record,
+		})
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Score > res[j].Score })
+	if len(res) > MaxCandidates {
+		res = res[:MaxCandidates]
+	}
+	return res, nil
+}
+
+// One mencari tepat satu data, *Error jika tidak ditemukan atau ambigu
+func (r *Resolver) One(ctx context.Context, c *zahir.Client, kind Kind, query string) (Candidate, error) {
+	cands, err := r.Resolve(ctx, c, kind, query)
+	if err != nil {
+		return Candidate{}, err
+	}
+	if best, ok := Pick(cands); ok {
+		return best, nil
+	}
+	return Candidate{}, &Error{Kind: kind, Query: query, Candidates: cands}
+}
+
+// Pick kandidat teratas jika tidak ada kandidat lain yang skornya berdekatan
+func Pick(cands []Candidate) (Candidate, bool) {
+	switch {
+	case len(cands) == 0:
+		return Candidate{}, false
+	case len(cands) == 1:
+		return cands[0], true
+	case cands[0].Score == 1 && cands[1].Score < 1:
+		return cands[0], true
+	case cands[0].Score-cands[1].Score >= Margin:
+		return cands[0], true
+	}
+	return Candidate{}, false
+}
+
+// load mengambil kontak dan produk slug dari cache atau dari Zahir jika sudah kedaluwarsa
+func (r *Resolver) load(ctx context.Context, c *zahir.Client) (*entry, error) {
+	r.mu.Lock()
+	e, ok := r.cache[c.Slug]
+	if !ok {
+		e = &entry{}
+		r.cache[c.Slug] = e
+	}
+	r.mu.Unlock()
+
+	// satu fetch per slug, request lain menunggu hasilnya
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.fetchedAt.IsZero() && time.Since(e.fetchedAt) < r.ttl {
+		return e, nil
+	}
+
+	contacts, err := c.ListContacts(ctx, zahir.NewFilter())
+	if err != nil {
+		return nil, err
+	}
+	products, err := c.ListProducts(ctx, zahir.NewFilter())
+	if err != nil {
+		return nil, err
+	}
+	if e.contacts, err = index(contacts); err != nil {
+		return nil, err
+	}
+	if e.products, err = index(products); err != nil {
+		return nil, err
+	}
+	e.fetchedAt = time.Now()
+	return e, nil
+}
+
+func index(v any) ([]indexed, error) {
+	records, err := aggregate.FromModels(v)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]indexed, 0, len(records))
+	for _, rec := range records {
+		name := str(rec, "name")
+		res = append(res, indexed{record: rec, name: name, tokens: tokens(name)})
+	}
+	return res, nil
+}
+
+// hasRole true jika kontak punya peran sesuai kind, Contact dan Product menerima semua data
+func hasRole(r aggregate.Record, kind Kind) bool {
+	switch kind {
+	case Customer, Supplier, Employee:
+		v, _ := r["is_"+string(kind)].(bool)
+		return v
+	}
+	return true
+}
+
+func str(r aggregate.Record, key string) string {
+	s, _ := r[key].(string)
+	return s
+}
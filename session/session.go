@@ -0,0 +1,182 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxData jumlah payload API terakhir yang disimpan per session, payload lebih lama dibuang
+	MaxData = 3
+	// MaxDataBytes panjang maksimal satu payload yang dikirim ulang ke AI
+	MaxDataBytes = 4000
+)
+
+// Message satu giliran percakapan yang dikirim ulang ke AI sebagai history
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// CacheEntry menyimpan payload API terakhir per endpoint
+type CacheEntry struct {
+	Endpoint string
+	Data     string
+}
+
+// Session menyimpan history chat dan data API milik satu user
+type Session struct {
+	ID        string
+	UpdatedAt time.Time
+
+	mu       sync.Mutex
+	maxTurns int
+	history  []Message
+	data     []CacheEntry
+	pending  any
+}
+
+// AddTurn menambahkan pesan ke history, giliran terlama dibuang jika melebihi maxTurns
+func (s *Session) AddTurn(role, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.history = append(s.history, Message{Role: role, Content: content})
+	// satu giliran = pesan user + jawaban assistant
+	if limit := s.maxTurns * 2; limit > 0 && len(s.history) > limit {
+		s.history = append([]Message{}, s.history[len(s.history)-limit:]...)
+	}
+	s.UpdatedAt = time.Now()
+}
+
+// SetData menyimpan payload API, payload lama untuk endpoint yang sama ditimpa. Hanya MaxData payload
+// terakhir yang disimpan dan masing-masing dipotong MaxDataBytes supaya prompt tidak terus membesar
+func (s *Session) SetData(endpoint, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, d := range s.data {
+		if d.Endpoint == endpoint {
+			s.data = append(s.data[:i], s.data[i+1:]...)
+			break
+		}
+	}
+	s.data = append(s.data, CacheEntry{Endpoint: endpoint, Data: truncate(data, MaxDataBytes)})
+	if len(s.data) > MaxData {
+		s.data = append([]CacheEntry{}, s.data[len(s.data)-MaxData:]...)
+	}
+	s.UpdatedAt = time.Now()
+}
+
+// truncate memotong data menjadi paling banyak n byte tanpa memotong karakter UTF-8
+func truncate(data string, n int) string {
+	if len(data) <= n {
+		return data
+	}
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	return data[:n] + "... (data dipotong)"
+}
+
+// ClearData menghapus seluruh payload API yang tersimpan
+func (s *Session) ClearData() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = nil
+}
+
+// SetPending menyimpan keputusan yang menunggu jawaban user, menggantikan yang sebelumnya
+func (s *Session) SetPending(v any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = v
+	s.UpdatedAt = time.Now()
+}
+
+// TakePending mengambil lalu membuang keputusan yang menunggu jawaban, nil jika tidak ada
+func (s *Session) TakePending() any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.pending
+	s.pending = nil
+	return v
+}
+
+// Messages mengembalikan payload API sebagai pesan system diikuti history chat
+func (s *Session) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := make([]Message, 0, len(s.data)+len(s.history))
+	for _, d := range s.data {
+		msgs = append(msgs, Message{Role: "system", Content: "data " + d.Endpoint + ":" + d.Data})
+	}
+	return append(msgs, s.history...)
+}
+
+// Store menyimpan session di memory dengan masa berlaku (TTL)
+type Store struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	maxTurns int
+	sessions map[string]*Session
+}
+
+// NewStore membuat store baru, ttl <= 0 berarti session tidak pernah kadaluarsa
+func NewStore(ttl time.Duration, maxTurns int) *Store {
+	return &Store{
+		ttl:      ttl,
+		maxTurns: maxTurns,
+		sessions: map[string]*Session{},
+	}
+}
+
+// Get mengambil session berdasarkan id, session baru dibuat jika belum ada atau sudah kadaluarsa
+func (st *Store) Get(id string) *Session {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	st.sweep(now)
+
+	sess, ok := st.sessions[id]
+	if !ok {
+		sess = &Session{ID: id, maxTurns: st.maxTurns}
+		st.sessions[id] = sess
+	}
+	sess.mu.Lock()
+	sess.UpdatedAt = now
+	sess.mu.Unlock()
+	return sess
+}
+
+// Delete menghapus session
+func (st *Store) Delete(id string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.sessions, id)
+}
+
+func (st *Store) sweep(now time.Time) {
+	if st.ttl <= 0 {
+		return
+	}
+	for id, sess := range st.sessions {
+		sess.mu.Lock()
+		expired := now.Sub(sess.UpdatedAt) > st.ttl
+		sess.mu.Unlock()
+		if expired {
+			delete(st.sessions, id)
+		}
+	}
+}
+
+// KeyFromCredential membuat id session dari bearer token + slug, session_id dari client ditambahkan di belakangnya
+func KeyFromCredential(bearerToken, slug string) string {
+	sum := sha256.Sum256([]byte(bearerToken + "|" + slug))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/MaulanaR/zai/auth"
+	"github.com/MaulanaR/zai/tenant"
+)
+
+// SettingsRequest body PUT /settings, field pengaturan yang kosong memakai default
+type SettingsRequest struct {
+	Slug string `json:"slug"`
+	tenant.Settings
+}
+
+// settingsHandler GET /settings?slug=... mengembalikan pengaturan tenant, PUT menggantinya (hanya admin).
+// Token dikirim lewat header Authorization, slug harus slug milik token tersebut
+func settingsHandler(bot *ChatBot) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			res tenant.Settings
+			err error
+		)
+		switch r.Method {
+		case http.MethodGet:
+			p, authErr := bot.authenticate(r, "", r.URL.Query().Get("slug"))
+			if authErr != nil {
+				writeAuthError(w, authErr)
+				return
+			}
+			res = bot.settings.Get(p.Slug)
+		case http.MethodPut:
+			var req SettingsRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			p, authErr := bot.authenticate(r, "", req.Slug)
+			if authErr != nil {
+				writeAuthError(w, authErr)
+				return
+			}
+			if !p.Can(auth.ManageSettings) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(forbidden(p, "mengubah pengaturan"))
+				return
+			}
+			res, err = bot.settings.Set(p.Slug, req.Settings)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ZahirResponse{Status: "error", Message: "Gagal menyimpan pengaturan: " + err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(res)
+	}
+}
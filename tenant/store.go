@@ -0,0 +1,100 @@
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store pengaturan per slug di memory, disimpan ke file JSON {"slug": {...}} jika path diisi
+type Store struct {
+	mu       sync.RWMutex
+	path     string
+	defaults Settings
+	tenants  map[string]Settings
+}
+
+// NewStore membuat store dengan pengaturan default dan membaca file path jika ada
+func NewStore(path string, defaults Settings) (*Store, error) {
+	if err := defaults.Validate(); err != nil {
+		return nil, err
+	}
+	s := &Store{path: path, defaults: defaults, tenants: map[string]Settings{}}
+	if path == "" {
+		return s, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &s.tenants); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for slug, t := range s.tenants {
+		if err := t.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, slug, err)
+		}
+	}
+	return s, nil
+}
+
+// Get pengaturan slug yang sudah digabung dengan default
+func (s *Store) Get(slug string) Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.defaults.merge(s.tenants[slug])
+}
+
+// Set mengganti pengaturan slug lalu menyimpannya ke file, mengembalikan hasil gabungan dengan default
+func (s *Store) Set(slug string, v Settings) (Settings, error) {
+	if slug == "" {
+		return Settings{}, fmt.Errorf("slug wajib diisi")
+	}
+	if err := v.Validate(); err != nil {
+		return Settings{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, existed := s.tenants[slug]
+	s.tenants[slug] = v
+	if err := s.save(); err != nil {
+		if existed {
+			s.tenants[slug] = prev
+		} else {
+			delete(s.tenants, slug)
+		}
+		return Settings{}, err
+	}
+	return s.defaults.merge(v), nil
+}
+
+// save menulis semua pengaturan ke file sementara lalu rename, dipanggil dengan mu terkunci
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(s.tenants, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".tenants-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,221 @@
+// Package tenant menyimpan pengaturan per tenant (slug Zahir): zona waktu, mata uang default,
+// format angka dan tanggal, bahasa jawaban serta kurs untuk konversi mata uang asing.
+package tenant
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Settings pengaturan satu tenant, field kosong memakai default
+type Settings struct {
+	Timezone    string `json:"timezone,omitempty"`           // nama IANA, misal Asia/Jakarta
+	Currency    string `json:"currency,omitempty"`           // kode ISO 4217 mata uang default, misal IDR
+	Language    string `json:"language,omitempty"`           // bahasa jawaban: id atau en
+	DecimalSep  string `json:"decimal_separator,omitempty"`  // pemisah desimal, "," atau "."
+	ThousandSep string `json:"thousand_separator,omitempty"` // pemisah ribuan, "." "," " " atau "'"
+	DateFormat  string `json:"date_format,omitempty"`        // salah satu DateFormats
+
+	// Rates kurs 1 unit mata uang asing dalam Currency, misal {"USD": 16250}
+	Rates map[string]float64 `json:"rates,omitempty"`
+	// Convert menampilkan nilai mata uang asing beserta konversinya ke Currency
+	Convert bool `json:"convert,omitempty"`
+}
+
+// Languages bahasa jawaban yang didukung
+var Languages = map[string]string{"id": "Bahasa Indonesia", "en": "English"}
+
+// DateFormats format tanggal yang didukung beserta layout Go-nya, MMMM diganti nama bulan sesuai bahasa
+var DateFormats = map[string]string{
+	"DD/MM/YYYY":  "02/01/2006",
+	"MM/DD/YYYY":  "01/02/2006",
+	"YYYY-MM-DD":  "2006-01-02",
+	"D MMMM YYYY": "2 January 2006",
+}
+
+var (
+	currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+	symbols      = map[string]string{"IDR": "Rp", "USD": "US$", "SGD": "S$", "EUR": "€", "JPY": "¥", "MYR": "RM", "CNY": "CN¥", "AUD": "A$"}
+	// mata uang tanpa pecahan
+	noDecimals = map[string]bool{"IDR": true, "JPY": true}
+	bulan      = []string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+)
+
+// Defaults pengaturan bawaan untuk bahasa lang, dipakai juga saat tenant mengganti bahasa
+func Defaults(lang string) Settings {
+	if lang == "en" {
+		return Settings{Timezone: "Asia/Jakarta", Currency: "IDR", Language: "en", DecimalSep: ".", ThousandSep: ",", DateFormat: "D MMMM YYYY"}
+	}
+	return Settings{Timezone: "Asia/Jakarta", Currency: "IDR", Language: "id", DecimalSep: ",", ThousandSep: ".", DateFormat: "DD/MM/YYYY"}
+}
+
+// Validate memeriksa setiap field yang diisi
+func (s Settings) Validate() error {
+	errs := []string{}
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			errs = append(errs, fmt.Sprintf("timezone %q tidak dikenal", s.Timezone))
+		}
+	}
+	if s.Currency != "" && !currencyCode.MatchString(s.Currency) {
+		errs = append(errs, "currency harus berupa kode ISO 4217, misal IDR")
+	}
+	if _, ok := Languages[s.Language]; s.Language != "" && !ok {
+		errs = append(errs, "language harus id atau en")
+	}
+	if s.DecimalSep != "" && s.DecimalSep != "," && s.DecimalSep != "." {
+		errs = append(errs, `decimal_separator harus "," atau "."`)
+	}
+	if s.ThousandSep != "" && (len(s.ThousandSep) != 1 || !strings.Contains(".,' ", s.ThousandSep)) {
+		errs = append(errs, `thousand_separator harus ".", ",", " " atau "'"`)
+	}
+	if s.DecimalSep != "" && s.DecimalSep == s.ThousandSep {
+		errs = append(errs, "decimal_separator dan thousand_separator tidak boleh sama")
+	}
+	if _, ok := DateFormats[s.DateFormat]; s.DateFormat != "" && !ok {
+		errs = append(errs, fmt.Sprintf("date_format %q tidak didukung", s.DateFormat))
+	}
+	for cur, rate := range s.Rates {
+		if !currencyCode.MatchString(cur) || rate <= 0 {
+			errs = append(errs, fmt.Sprintf("rates.%s harus lebih dari 0 dengan kode ISO 4217", cur))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// merge menimpa s dengan field o yang diisi; ganti bahasa berarti format angka/tanggal ikut default bahasa tersebut
+func (s Settings) merge(o Settings) Settings {
+	if o.Language != "" && o.Language != s.Language {
+		d := Defaults(o.Language)
+		s.Language, s.DecimalSep, s.ThousandSep, s.DateFormat = d.Language, d.DecimalSep, d.ThousandSep, d.DateFormat
+	}
+	if o.Timezone != "" {
+		s.Timezone = o.Timezone
+	}
+	if o.Currency != "" {
+		s.Currency = o.Currency
+	}
+	if o.DecimalSep != "" {
+		s.DecimalSep = o.DecimalSep
+	}
+	if o.ThousandSep != "" {
+		s.ThousandSep = o.ThousandSep
+	}
+	if o.DateFormat != "" {
+		s.DateFormat = o.DateFormat
+	}
+	if o.Rates != nil {
+		s.Rates = o.Rates
+	}
+	s.Convert = o.Convert
+	return s
+}
+
+// Location zona waktu tenant, UTC jika Timezone tidak valid
+func (s Settings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Now waktu sekarang di zona waktu tenant
+func (s Settings) Now() time.Time {
+	return time.Now().In(s.Location())
+}
+
+// LanguageName nama bahasa jawaban, misal "Bahasa Indonesia"
+func (s Settings) LanguageName() string {
+	if name, ok := Languages[s.Language]; ok {
+		return name
+	}
+	return Languages["id"]
+}
+
+// Number format angka dengan pemisah tenant, maksimal dua desimal
+func (s Settings) Number(v float64) string {
+	str := s.digits(v, 2)
+	if strings.Contains(str, s.DecimalSep) {
+		str = strings.TrimRight(strings.TrimRight(str, "0"), s.DecimalSep)
+	}
+	return str
+}
+
+// Money format nominal dalam currency (kosong berarti mata uang tenant), misal "Rp 1.250.000".
+// Jika Convert aktif dan kurs tersedia, nilai mata uang asing diberi konversinya
+func (s Settings) Money(v float64, currency string) string {
+	if currency == "" {
+		currency = s.Currency
+	}
+	str := s.money(v, currency)
+	if converted, ok := s.Exchange(v, currency); ok && s.Convert && currency != s.Currency {
+		str += " (≈ " + s.money(converted, s.Currency) + ")"
+	}
+	return str
+}
+
+func (s Settings) money(v float64, currency string) string {
+	decimals := 2
+	if noDecimals[currency] {
+		decimals = 0
+	}
+	symbol, ok := symbols[currency]
+	if !ok {
+		symbol = currency
+	}
+	return symbol + " " + s.digits(v, decimals)
+}
+
+// Exchange mengubah nominal currency ke mata uang tenant memakai Rates
+func (s Settings) Exchange(v float64, currency string) (float64, bool) {
+	if currency == "" || currency == s.Currency {
+		return v, true
+	}
+	rate, ok := s.Rates[currency]
+	if !ok {
+		return 0, false
+	}
+	return v * rate, true
+}
+
+// Date format tanggal YYYY-MM-DD (atau RFC 3339) sesuai DateFormat, selain itu dikembalikan apa adanya
+func (s Settings) Date(v string) string {
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, v); err != nil {
+			return v
+		}
+	}
+	layout, ok := DateFormats[s.DateFormat]
+	if !ok {
+		layout = DateFormats["DD/MM/YYYY"]
+	}
+	str := t.Format(layout)
+	if s.Language == "id" && strings.Contains(layout, "January") {
+		str = strings.Replace(str, t.Month().String(), bulan[t.Month()-1], 1)
+	}
+	return str
+}
+
+// digits angka dengan pemisah ribuan dan desimal tenant
+func (s Settings) digits(v float64, decimals int) string {
+	str := fmt.Sprintf("%.*f", decimals, math.Abs(v))
+	whole, frac, _ := strings.Cut(str, ".")
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + s.ThousandSep + whole[i:]
+	}
+	if frac != "" {
+		whole += s.DecimalSep + frac
+	}
+	if v < 0 && strings.Trim(str, "0.") != "" {
+		whole = "-" + whole
+	}
+	return whole
+}
@@ -0,0 +1,480 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/MaulanaR/zai/ai"
+	"github.com/MaulanaR/zai/resolve"
+)
+
+// Param satu argumen tool beserta nama field/query param di Zahir API
+type Param struct {
+	Name        string
+	Key         string // query param (GET) atau field body (POST), kosong berarti sama dengan Name
+	Type        string // string, number, integer, boolean, array
+	Description string
+	Enum        []string
+	Required    bool
+	Default     any          // nilai jika model tidak mengisi argumen ini
+	Local       bool         // tidak dikirim ke Zahir API, dipakai untuk agregasi lokal atau mencari data yang diubah
+	Items       []Param      // field setiap object untuk Type array, kosong berarti array of string
+	Entity      resolve.Kind // nilai berupa nama kontak/produk, dicocokkan dengan resolver sebelum query
+}
+
+// Definition satu endpoint Zahir yang diekspos ke model sebagai tool
+type Definition struct {
+	Name        string
+	Description string
+	Endpoint    string
+	Method      string
+	Type        string // jenis data input, dipakai ProcessMessage untuk POST/PATCH/DELETE
+	Aggregate   bool   // data diagregasi lokal, model hanya menerima hasil hitungan
+	Params      []Param
+}
+
+// Schema membuat JSON Schema dari daftar Params
+func (d Definition) Schema() map[string]any {
+	return objectSchema(d.Params)
+}
+
+func objectSchema(params []Param) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	for _, p := range params {
+		prop := map[string]any{"type": p.Type}
+		if p.Description != "" {
+			prop["description"] = p.Description
+		}
+		if len(p.Enum) > 0 {
+			prop["enum"] = p.Enum
+		}
+		if p.Default != nil {
+			prop["default"] = p.Default
+		}
+		if p.Type == "array" {
+			prop["items"] = map[string]any{"type": "string"}
+			if len(p.Items) > 0 {
+				prop["items"] = objectSchema(p.Items)
+			}
+		}
+		props[p.Name] = prop
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Tool deklarasi tool untuk dikirim ke LLM
+func (d Definition) Tool() ai.Tool {
+	return ai.Tool{Name: d.Name, Description: d.Description, Parameters: d.Schema()}
+}
+
+// ParseArgs memvalidasi argumen dari model dan mengubahnya menjadi params Zahir API
+func (d Definition) ParseArgs(raw json.RawMessage) (map[string]any, error) {
+	args := map[string]any{}
+	if len(strings.TrimSpace(string(raw))) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, fmt.Errorf("tool %s: arguments is not a JSON object: %v", d.Name, err)
+		}
+	}
+
+	errs := []string{}
+	params := parseObject(d.Params, args, "", &errs)
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("tool %s: invalid arguments: %s", d.Name, strings.Join(errs, "; "))
+	}
+	return params, nil
+}
+
+// parseObject memvalidasi satu object argumen, nama field pada error diberi prefix
+func parseObject(defs []Param, args map[string]any, prefix string, errs *[]string) map[string]any {
+	byName := map[string]Param{}
+	for _, p := range defs {
+		byName[p.Name] = p
+	}
+
+	for _, p := range defs {
+		if _, ok := args[p.Name]; p.Required && !ok {
+			*errs = append(*errs, prefix+p.Name+" is required")
+		}
+	}
+
+	params := map[string]any{}
+	for _, p := range defs {
+		if v, ok := args[p.Name]; p.Default != nil && (!ok || v == nil) {
+			params[p.key()] = p.Default
+		}
+	}
+	for name, v := range args {
+		p, ok := byName[name]
+		if !ok {
+			*errs = append(*errs, prefix+name+" is not allowed")
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		if err := checkType(p, v); err != nil {
+			*errs = append(*errs, prefix+err.Error())
+			continue
+		}
+		if p.Type == "array" && len(p.Items) == 0 {
+			items := []string{}
+			for i, it := range v.([]any) {
+				s, ok := it.(string)
+				if !ok {
+					*errs = append(*errs, fmt.Sprintf("%s%s[%d] must be string", prefix, p.Name, i))
+					continue
+				}
+				items = append(items, s)
+			}
+			v = items
+		} else if p.Type == "array" {
+			items := []map[string]any{}
+			for i, it := range v.([]any) {
+				obj, ok := it.(map[string]any)
+				if !ok {
+					*errs = append(*errs, fmt.Sprintf("%s%s[%d] must be object", prefix, p.Name, i))
+					continue
+				}
+				items = append(items, parseObject(p.Items, obj, fmt.Sprintf("%s%s[%d].", prefix, p.Name, i), errs))
+			}
+			v = items
+		}
+		params[p.key()] = v
+	}
+	return params
+}
+
+// key nama query param atau field body untuk argumen ini
+func (p Param) key() string {
+	if p.Key == "" {
+		return p.Name
+	}
+	return p.Key
+}
+
+// Split memisahkan params hasil ParseArgs menjadi params Zahir API dan params lokal (Param.Local)
+func (d Definition) Split(params map[string]any) (api, local map[string]any) {
+	api, local = map[string]any{}, map[string]any{}
+	for k, v := range params {
+		api[k] = v
+	}
+	for _, p := range d.Params {
+		if v, ok := api[p.key()]; ok && p.Local {
+			local[p.Name] = v
+			delete(api, p.key())
+		}
+	}
+	return api, local
+}
+
+func checkType(p Param, v any) error {
+	ok := false
+	switch p.Type {
+	case "string":
+		_, ok = v.(string)
+	case "number":
+		_, ok = v.(float64)
+	case "integer":
+		f, isNum := v.(float64)
+		ok = isNum && f == float64(int64(f))
+	case "boolean":
+		_, ok = v.(bool)
+	case "array":
+		_, ok = v.([]any)
+	default:
+		ok = true
+	}
+	if !ok {
+		return fmt.Errorf("%s must be %s", p.Name, p.Type)
+	}
+	if len(p.Enum) > 0 {
+		for _, e := range p.Enum {
+			if fmt.Sprint(v) == e {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s", p.Name, strings.Join(p.Enum, ", "))
+	}
+	return nil
+}
+
+// Find mencari definisi tool berdasarkan nama
+func Find(defs []Definition, name string) (Definition, bool) {
+	for _, d := range defs {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// AITools mengubah daftar definisi menjadi deklarasi tool untuk LLM
+func AITools(defs []Definition) []ai.Tool {
+	res := make([]ai.Tool, 0, len(defs))
+	for _, d := range defs {
+		res = append(res, d.Tool())
+	}
+	return res
+}
+
+var (
+	perPage         = Param{Name: "per_page", Type: "string", Default: "10", Description: "Number of rows"}
+	contactsPerPage = Param{Name: "per_page", Type: "string", Default: "50", Description: "Number of rows"}
+	dateGte         = Param{Name: "date_from", Key: "date[$gte]", Type: "string", Description: "Start date filter (inclusive), format YYYY-MM-DD, from resolve_period for relative periods"}
+	dateLte         = Param{Name: "date_to", Key: "date[$lte]", Type: "string", Description: "End date filter (inclusive), format YYYY-MM-DD, from resolve_period for relative periods"}
+	dateEq          = Param{Name: "date", Key: "date[$eq]", Type: "string", Description: "Exact date filter, format YYYY-MM-DD"}
+
+	metric   = Param{Name: "metric", Type: "string", Enum: []string{"sum", "count", "avg", "min", "max"}, Required: true, Local: true}
+	period   = Param{Name: "period", Type: "string", Enum: []string{"day", "month", "year"}, Local: true, Description: "Bucket size when grouping by date"}
+	top      = Param{Name: "top", Type: "integer", Local: true, Description: "Only return the N best groups (top-N)"}
+	lineItem = []Param{
+		{Name: "product", Type: "string", Required: true, Description: "Product code or name"},
+		{Name: "quantity", Type: "number", Required: true},
+		{Name: "unit_price", Type: "number", Description: "Default is the product selling price (sales) or cost (purchase)"},
+		{Name: "discount", Type: "number", Description: "Discount amount for this line"},
+	}
+	contactTarget = Param{Name: "target", Type: "string", Required: true, Local: true, Description: "Current name of the contact"}
+	productTarget = Param{Name: "target", Type: "string", Required: true, Local: true, Description: "Current code or name of the product"}
+	order         = Param{Name: "order", Type: "string", Enum: []string{"desc", "asc"}, Local: true, Description: `Sort by value, default "desc" (largest first). When grouped by period without top, rows are sorted by date, default "asc" (oldest first)`}
+)
+
+// AskUser tool untuk bertanya balik ke user jika permintaan ambigu, bukan endpoint Zahir
+var AskUser = Definition{
+	Name:        "ask_user",
+	Description: "Ask the user a short clarifying question instead of guessing, e.g. which period when they ask for invoices without a date. The question is shown with the choices as buttons and the original request continues with the answer",
+	Params: []Param{
+		{Name: "question", Type: "string", Required: true, Description: "Question in the user's language"},
+		{Name: "choices", Type: "array", Description: "2 to 5 short answer options"},
+		{Name: "slot", Type: "string", Description: "The missing information, e.g. period, customer, product"},
+	},
+}
+
+// ResolvePeriod tool untuk mengubah periode relatif menjadi tanggal, dihitung di server bukan oleh model
+var ResolvePeriod = Definition{
+	Name:        "resolve_period",
+	Description: `Convert a period the user mentions ("bulan lalu", "minggu ini", "Q3 tahun lalu", "kemarin", "last 30 days", "januari sampai maret") into exact dates. Returns {"from","to","label"} in YYYY-MM-DD; pass from as date_from and to as date_to (or date for a single day)`,
+	Params: []Param{
+		{Name: "expression", Type: "string", Required: true, Description: "The period exactly as the user wrote it"},
+	},
+}
+
+// Zahir daftar endpoint Zahir yang bisa dipanggil model
+var Zahir = []Definition{
+	{
+		Name:        "list_contacts",
+		Description: "Get contacts: customers, suppliers (vendors) and employees",
+		Endpoint:    "contacts",
+		Method:      http.MethodGet,
+		Params: []Param{
+			contactsPerPage,
+			{Name: "name", Type: "string", Description: "Contact name", Entity: resolve.Contact},
+			{Name: "is_customer", Type: "boolean"},
+			{Name: "is_supplier", Type: "boolean"},
+			{Name: "is_employee", Type: "boolean"},
+			{Name: "is_salesman", Type: "boolean"},
+			{Name: "is_active", Type: "boolean"},
+			{Name: "customer_category", Key: "customer_category.name", Type: "string", Description: "Customer category name"},
+		},
+	},
+	{
+		Name:        "list_sales_invoices",
+		Description: "Get sales invoices",
+		Endpoint:    "sales_invoices",
+		Method:      http.MethodGet,
+		Params: []Param{
+			perPage, dateGte, dateLte, dateEq,
+			{Name: "customer_name", Key: "customer.name", Type: "string", Description: "Customer name", Entity: resolve.Customer},
+			{Name: "payment_status", Type: "string", Enum: []string{"open", "paid"}},
+			{Name: "number", Type: "string", Description: "Invoice number"},
+			{Name: "include_line_items", Key: "includes[line_items]", Type: "string", Enum: []string{"true"}, Description: "Set when the user needs the products of the invoices"},
+		},
+	},
+	{
+		Name:        "list_products",
+		Description: "Get products with stock and prices",
+		Endpoint:    "products",
+		Method:      http.MethodGet,
+		Params: []Param{
+			perPage,
+			{Name: "code", Type: "string", Description: "Product code"},
+			{Name: "name", Type: "string", Description: "Product name", Entity: resolve.Product},
+			{Name: "category", Key: "category.name", Type: "string", Description: "Product category name"},
+		},
+	},
+	{
+		Name:        "list_purchase_invoices",
+		Description: "Get purchase invoices",
+		Endpoint:    "purchases_invoices",
+		Method:      http.MethodGet,
+		Params: []Param{
+			perPage, dateGte, dateLte, dateEq,
+			{Name: "number", Type: "string", Description: "Invoice number"},
+		},
+	},
+	{
+		Name:        "aggregate_sales_invoices",
+		Description: "Compute totals, counts, averages, min/max and rankings of sales invoices. Use this instead of list_sales_invoices for any calculation (total sales, sales per customer/product/month, best customer, etc)",
+		Endpoint:    "sales_invoices",
+		Method:      http.MethodGet,
+		Aggregate:   true,
+		Params: []Param{
+			dateGte, dateLte, dateEq,
+			{Name: "customer_name", Key: "customer.name", Type: "string", Description: "Customer name", Entity: resolve.Customer},
+			{Name: "payment_status", Type: "string", Enum: []string{"open", "paid"}},
+			metric, period, top, order,
+			{Name: "group_by", Type: "string", Local: true, Description: "Group field, omit for a single grand total", Enum: []string{
+				"customer.name", "payment_status", "status", "currency.name", "date",
+				"line_items.product.name", "line_items.product.category.name",
+			}},
+			{Name: "field", Type: "string", Local: true, Description: "Numeric field, required unless metric is count. line_items.amount is quantity x unit price minus discount", Enum: []string{
+				"total_amount", "total_payment", "line_items.quantity", "line_items.amount",
+			}},
+		},
+	},
+	{
+		Name:        "aggregate_purchase_invoices",
+		Description: "Compute totals, counts, averages, min/max and rankings of purchase invoices. Use this instead of list_purchase_invoices for any calculation",
+		Endpoint:    "purchases_invoices",
+		Method:      http.MethodGet,
+		Aggregate:   true,
+		Params: []Param{
+			dateGte, dateLte, dateEq,
+			metric, period, top, order,
+			{Name: "group_by", Type: "string", Local: true, Description: "Group field, omit for a single grand total", Enum: []string{"date"}},
+			{Name: "field", Type: "string", Local: true, Description: "Numeric field, required unless metric is count", Enum: []string{"total_amount"}},
+		},
+	},
+	{
+		Name:        "get_profit_loss",
+		Description: "Get the simple profit and loss report",
+		Endpoint:    "dashboards/profit_loss_simple",
+		Method:      http.MethodGet,
+		Params:      []Param{dateGte, dateLte},
+	},
+	{
+		Name:        "get_balance_sheet",
+		Description: "Get the simple balance sheet report",
+		Endpoint:    "dashboards/balance_sheet_simple",
+		Method:      http.MethodGet,
+		Params:      []Param{dateLte},
+	},
+	{
+		Name:        "create_contact",
+		Description: "Create a new contact (customer, supplier or employee). name, phone and email are required; call it with what the user gave, missing fields are asked back by the server",
+		Endpoint:    "contacts",
+		Method:      http.MethodPost,
+		Type:        "kontak",
+		Params: []Param{
+			{Name: "name", Type: "string", Required: true},
+			{Name: "phone", Type: "string"},
+			{Name: "email", Type: "string", Description: "Valid email address"},
+			{Name: "is_customer", Type: "boolean"},
+			{Name: "is_supplier", Type: "boolean"},
+			{Name: "is_employee", Type: "boolean"},
+		},
+	},
+	{
+		Name:        "create_product",
+		Description: "Create a new product. name, price and category are required; call it with what the user gave, missing fields are asked back by the server",
+		Endpoint:    "products",
+		Method:      http.MethodPost,
+		Type:        "products",
+		Params: []Param{
+			{Name: "name", Type: "string", Required: true},
+			{Name: "price", Type: "number"},
+			{Name: "category", Type: "string"},
+		},
+	},
+	{
+		Name:        "create_sales_invoice",
+		Description: "Create a sales invoice. A draft is shown to the user and only saved after the user confirms it",
+		Endpoint:    "sales_invoices",
+		Method:      http.MethodPost,
+		Type:        "sales_invoices",
+		Params: []Param{
+			{Name: "customer_name", Type: "string", Required: true, Description: "Customer name as written by the user"},
+			{Name: "date", Type: "string", Description: "Invoice date YYYY-MM-DD, default today"},
+			{Name: "description", Type: "string"},
+			{Name: "tax_rate", Type: "number", Description: "Tax percentage applied after discount, e.g. 11 for PPN 11%. Default 0"},
+			{Name: "line_items", Type: "array", Required: true, Items: lineItem},
+		},
+	},
+	{
+		Name:        "create_purchase_invoice",
+		Description: "Record a purchase invoice from a supplier. A draft is shown to the user and only saved after the user confirms it",
+		Endpoint:    "purchases_invoices",
+		Method:      http.MethodPost,
+		Type:        "purchases_invoices",
+		Params: []Param{
+			{Name: "supplier_name", Type: "string", Required: true, Description: "Supplier name as written by the user"},
+			{Name: "date", Type: "string", Description: "Invoice date YYYY-MM-DD, default today, cannot be in the future"},
+			{Name: "description", Type: "string"},
+			{Name: "tax_rate", Type: "number", Description: "Tax percentage applied after discount, e.g. 11 for PPN 11%. Default 0"},
+			{Name: "line_items", Type: "array", Required: true, Items: lineItem},
+		},
+	},
+	{
+		Name:        "update_contact",
+		Description: "Change fields of an existing contact, e.g. rename, change phone or deactivate (is_active false). Only send the fields that change. A before/after diff is shown and only saved after the user confirms it",
+		Endpoint:    "contacts",
+		Method:      http.MethodPatch,
+		Type:        "kontak",
+		Params: []Param{
+			contactTarget,
+			{Name: "new_name", Key: "name", Type: "string"},
+			{Name: "phone", Type: "string"},
+			{Name: "email", Type: "string", Description: "Valid email address"},
+			{Name: "is_customer", Type: "boolean"},
+			{Name: "is_supplier", Type: "boolean"},
+			{Name: "is_employee", Type: "boolean"},
+			{Name: "is_active", Type: "boolean", Description: "false to deactivate the contact"},
+		},
+	},
+	{
+		Name:        "update_product",
+		Description: "Change fields of an existing product, e.g. selling price or name. Only send the fields that change. A before/after diff is shown and only saved after the user confirms it",
+		Endpoint:    "products",
+		Method:      http.MethodPatch,
+		Type:        "products",
+		Params: []Param{
+			productTarget,
+			{Name: "new_name", Key: "name", Type: "string"},
+			{Name: "new_code", Key: "code", Type: "string"},
+			{Name: "description", Type: "string"},
+			{Name: "unit_price", Type: "number", Description: "Selling price"},
+			{Name: "unit_cogs", Type: "number", Description: "Cost price"},
+		},
+	},
+	{
+		Name:        "delete_contact",
+		Description: "Permanently delete a contact. Prefer update_contact with is_active false when the user asks to deactivate",
+		Endpoint:    "contacts",
+		Method:      http.MethodDelete,
+		Type:        "kontak",
+		Params:      []Param{contactTarget},
+	},
+	{
+		Name:        "delete_product",
+		Description: "Permanently delete a product",
+		Endpoint:    "products",
+		Method:      http.MethodDelete,
+		Type:        "products",
+		Params:      []Param{productTarget},
+	},
+}
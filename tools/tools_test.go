@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseArgsDefault(t *testing.T) {
+	tests := []struct {
+		tool string
+		args string
+		want any // nilai per_page, nil berarti tidak ada
+	}{
+		{"list_contacts", `{}`, "50"},
+		{"list_sales_invoices", `{"payment_status": "open"}`, "10"},
+		{"list_sales_invoices", `{"per_page": "5"}`, "5"},
+		{"list_products", `{"per_page": null}`, "10"},
+		{"aggregate_sales_invoices", `{"metric": "sum"}`, nil},
+	}
+	for _, tt := range tests {
+		d, ok := Find(Zahir, tt.tool)
+		if !ok {
+			t.Fatalf("tool %s tidak ditemukan", tt.tool)
+		}
+		params, err := d.ParseArgs(json.RawMessage(tt.args))
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.tool, tt.args, err)
+		}
+		if got := params["per_page"]; got != tt.want {
+			t.Errorf("%s %s: per_page = %v, want %v", tt.tool, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestSchemaDefault(t *testing.T) {
+	d, _ := Find(Zahir, "list_contacts")
+	prop := d.Schema()["properties"].(map[string]any)["per_page"].(map[string]any)
+	if prop["default"] != "50" {
+		t.Errorf("schema per_page default = %v, want 50", prop["default"])
+	}
+}
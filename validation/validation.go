@@ -0,0 +1,128 @@
+// Package validation memvalidasi payload input dengan tag `validate` (go-playground/validator).
+// Nama field pada error memakai tag json sehingga bisa langsung dipetakan ke field form.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+// FieldError satu field yang kosong atau tidak valid
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Errors daftar field yang gagal validasi
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, f := range e {
+		msgs[i] = f.Message
+	}
+	return strings.Join(msgs, ", ")
+}
+
+// Decode mengisi dst (pointer struct) dari params lalu memvalidasinya, nil jika valid
+func Decode(params map[string]any, dst any) Errors {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return Errors{{Rule: "json", Message: err.Error()}}
+	}
+	if err := json.Unmarshal(b, dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return Errors{{
+				Field:   typeErr.Field,
+				Rule:    "type",
+				Param:   typeErr.Type.String(),
+				Message: fmt.Sprintf("%s harus berupa %s", typeErr.Field, typeName(typeErr.Type)),
+			}}
+		}
+		return Errors{{Rule: "json", Message: err.Error()}}
+	}
+	return Struct(dst)
+}
+
+// Struct memvalidasi struct dengan tag `validate`, nil jika valid
+func Struct(v any) Errors {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return Errors{{Rule: "invalid", Message: err.Error()}}
+	}
+
+	res := make(Errors, 0, len(verrs))
+	for _, fe := range verrs {
+		res = append(res, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message(fe),
+		})
+	}
+	return res
+}
+
+// message pesan error per aturan validasi
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s wajib diisi", fe.Field())
+	case "email":
+		return fmt.Sprintf("%s harus berupa email yang valid", fe.Field())
+	case "gte":
+		return fmt.Sprintf("%s minimal %s", fe.Field(), fe.Param())
+	case "gt":
+		return fmt.Sprintf("%s harus lebih dari %s", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s harus salah satu dari %s", fe.Field(), fe.Param())
+	default:
+		return fmt.Sprintf("%s tidak valid (%s)", fe.Field(), fe.Tag())
+	}
+}
+
+// typeName nama tipe untuk pesan error
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "ya/tidak"
+	case reflect.String:
+		return "teks"
+	case reflect.Slice, reflect.Array:
+		return "daftar"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return "angka"
+	}
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// nama field diambil dari tag json, misal "email" bukan "Email"
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
@@ -0,0 +1,267 @@
+package zahir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MaulanaR/zai/logging"
+	"github.com/MaulanaR/zai/model"
+	"grest.dev/grest"
+)
+
+const (
+	DefaultPageSize = 100
+	DefaultMaxPages = 100
+)
+
+// Client Zahir API untuk satu bearer token + slug
+type Client struct {
+	BaseURL     string
+	BearerToken string
+	Slug        string
+	HTTP        *http.Client
+
+	// PageSize jumlah baris per halaman saat iterasi otomatis
+	PageSize int
+	// MaxPages batas jumlah halaman yang diambil dalam satu List
+	MaxPages int
+}
+
+func NewClient(baseURL, bearerToken, slug string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &Client{
+		BaseURL:     strings.TrimRight(baseURL, "/"),
+		BearerToken: bearerToken,
+		Slug:        slug,
+		HTTP:        httpClient,
+		PageSize:    DefaultPageSize,
+		MaxPages:    DefaultMaxPages,
+	}
+}
+
+// page satu halaman response list
+type page[T any] struct {
+	Data       []T `json:"results"`
+	Count      int `json:"count"`
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	TotalPages int `json:"total_pages"`
+}
+
+func (c *Client) ListContacts(ctx context.Context, f *Filter) ([]model.Contact, error) {
+	return list[model.Contact](ctx, c, "contacts", f)
+}
+
+func (c *Client) ListSalesInvoices(ctx context.Context, f *Filter) ([]model.SalesInvoiceDetail, error) {
+	return list[model.SalesInvoiceDetail](ctx, c, "sales_invoices", f)
+}
+
+func (c *Client) ListProducts(ctx context.Context, f *Filter) ([]model.Product, error) {
+	return list[model.Product](ctx, c, "products", f)
+}
+
+func (c *Client) ListPurchaseInvoices(ctx context.Context, f *Filter) ([]model.PurchaseInvDetail, error) {
+	return list[model.PurchaseInvDetail](ctx, c, "purchases_invoices", f)
+}
+
+// Dashboard mengambil data dashboard, misal "dashboards/profit_loss_simple"
+func (c *Client) Dashboard(ctx context.Context, endpoint string, f *Filter) (any, error) {
+	body, err := c.do(ctx, http.MethodGet, endpoint, f.Values().Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var d any
+	if err := json.Unmarshal(body, &d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// List mengambil data berdasarkan nama endpoint, dipakai oleh tool calling
+func (c *Client) List(ctx context.Context, endpoint string, f *Filter) (any, error) {
+	switch endpoint {
+	case "contacts":
+		return c.ListContacts(ctx, f)
+	case "sales_invoices":
+		return c.ListSalesInvoices(ctx, f)
+	case "products":
+		return c.ListProducts(ctx, f)
+	case "purchases_invoices":
+		return c.ListPurchaseInvoices(ctx, f)
+	default:
+		return c.Dashboard(ctx, endpoint, f)
+	}
+}
+
+// Ping memeriksa bearer token dan slug dengan request list terkecil, dipakai untuk verifikasi token
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodGet, "contacts", "page=1&per_page=1", nil)
+	return err
+}
+
+// Get mengambil satu data dengan ID tertentu dalam bentuk aslinya, termasuk field yang tidak ada di model
+func (c *Client) Get(ctx context.Context, endpoint, id string) (map[string]any, error) {
+	body, err := c.do(ctx, http.MethodGet, endpoint+"/"+id, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	res := struct {
+		Data map[string]any `json:"results"`
+	}{}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("zahir: decode %s/%s: %w", endpoint, id, err)
+	}
+	if res.Data == nil {
+		return nil, fmt.Errorf("zahir: %s/%s tidak berisi data", endpoint, id)
+	}
+	return res.Data, nil
+}
+
+func (c *Client) CreateContact(ctx context.Context, input map[string]any) (map[string]any, error) {
+	return c.Create(ctx, "contacts", input)
+}
+
+func (c *Client) CreateProduct(ctx context.Context, input map[string]any) (map[string]any, error) {
+	return c.Create(ctx, "products", input)
+}
+
+// Create mengirim POST ke endpoint dan mengembalikan data yang dibuat
+func (c *Client) Create(ctx context.Context, endpoint string, input any) (map[string]any, error) {
+	return c.write(ctx, http.MethodPost, endpoint, input)
+}
+
+// Patch mengubah sebagian field data dengan ID tertentu
+func (c *Client) Patch(ctx context.Context, endpoint, id string, input any) (map[string]any, error) {
+	return c.write(ctx, http.MethodPatch, endpoint+"/"+id, input)
+}
+
+// Put mengganti seluruh data dengan ID tertentu
+func (c *Client) Put(ctx context.Context, endpoint, id string, input any) (map[string]any, error) {
+	return c.write(ctx, http.MethodPut, endpoint+"/"+id, input)
+}
+
+// Delete menghapus data dengan ID tertentu
+func (c *Client) Delete(ctx context.Context, endpoint, id string) (map[string]any, error) {
+	return c.write(ctx, http.MethodDelete, endpoint+"/"+id, nil)
+}
+
+// write mengirim body JSON (nil berarti tanpa body) dan membaca response sebagai object
+func (c *Client) write(ctx context.Context, method, endpoint string, input any) (map[string]any, error) {
+	var jsonData []byte
+	if input != nil {
+		var err error
+		if jsonData, err = json.Marshal(input); err != nil {
+			return nil, err
+		}
+	}
+	body, err := c.do(ctx, method, endpoint, "", jsonData)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]any{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// list mengambil semua halaman sampai habis, sampai Limit filter atau MaxPages tercapai.
+// Jika filter berisi page, hanya halaman itu yang diambil
+func list[T any](ctx context.Context, c *Client, endpoint string, f *Filter) ([]T, error) {
+	if f == nil {
+		f = NewFilter()
+	}
+	q := f.Values()
+	single := q.Get("page") != ""
+
+	pageSize := c.PageSize
+	if f.limit > 0 && f.limit < pageSize {
+		pageSize = f.limit
+	}
+	if n, err := strconv.Atoi(q.Get("per_page")); err == nil && n > 0 {
+		pageSize = n
+	} else {
+		q.Set("per_page", strconv.Itoa(pageSize))
+	}
+
+	res := []T{}
+	for n := 1; ; n++ {
+		if !single {
+			q.Set("page", strconv.Itoa(n))
+		}
+		body, err := c.do(ctx, http.MethodGet, endpoint, q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		p := page[T]{}
+		if err := grest.NewJSON(body, true).ToFlat().Unmarshal(&p); err != nil {
+			return nil, fmt.Errorf("zahir: decode %s: %w", endpoint, err)
+		}
+		res = append(res, p.Data...)
+
+		if f.limit > 0 && len(res) >= f.limit {
+			return res[:f.limit], nil
+		}
+		last := len(p.Data) == 0 || len(p.Data) < pageSize
+		if p.TotalPages > 0 {
+			last = n >= p.TotalPages
+		}
+		if single || last || n >= c.MaxPages {
+			return res, nil
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint, query string, body []byte) ([]byte, error) {
+	urlStr := c.BaseURL + "/" + strings.Trim(strings.TrimSpace(endpoint), "/")
+	if query != "" {
+		urlStr += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	req.Header.Set("slug", c.Slug)
+	req.Header.Set("Content-Type", "application/json")
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
+
+	start := time.Now()
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		observe(method, endpoint, 0, time.Since(start))
+		slog.WarnContext(ctx, "zahir call", "method", method, "endpoint", endpoint, "duration_ms", time.Since(start).Milliseconds(), "err", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	observe(method, endpoint, resp.StatusCode, time.Since(start))
+	// query bisa berisi nama customer atau kontak dari filter, hanya dicatat di level debug
+	slog.InfoContext(ctx, "zahir call", "method", method, "endpoint", endpoint,
+		"status", resp.StatusCode, "duration_ms", time.Since(start).Milliseconds())
+	slog.DebugContext(ctx, "zahir call query", "endpoint", endpoint, "query", query, "bytes", len(respBody))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newAPIError(resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
@@ -0,0 +1,59 @@
+package zahir
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// APIError error dari response Zahir API dengan status non-2xx
+type APIError struct {
+	StatusCode int
+	Message    string
+	Detail     any
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("zahir: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("zahir: status %d: %s", e.StatusCode, e.Message)
+}
+
+// newAPIError membaca body error, baik {"message": ...} maupun {"error": {"message": ..., "detail": ...}}
+func newAPIError(status int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: status, Body: body}
+
+	var r struct {
+		Message string          `json:"message"`
+		Detail  any             `json:"detail"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		apiErr.Message = string(body)
+		return apiErr
+	}
+	apiErr.Message = r.Message
+	apiErr.Detail = r.Detail
+
+	if len(r.Error) > 0 {
+		var nested struct {
+			Message string `json:"message"`
+			Detail  any    `json:"detail"`
+		}
+		if err := json.Unmarshal(r.Error, &nested); err == nil {
+			if nested.Message != "" {
+				apiErr.Message = nested.Message
+			}
+			if nested.Detail != nil {
+				apiErr.Detail = nested.Detail
+			}
+		} else {
+			var msg string
+			if json.Unmarshal(r.Error, &msg) == nil && apiErr.Message == "" {
+				apiErr.Message = msg
+			}
+		}
+	}
+	return apiErr
+}
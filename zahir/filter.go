@@ -0,0 +1,91 @@
+package zahir
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Filter builder query param Zahir API, operator ditulis sebagai field[$op]=value
+type Filter struct {
+	values url.Values
+	limit  int
+}
+
+func NewFilter() *Filter {
+	return &Filter{values: url.Values{}}
+}
+
+// FilterFromParams membuat filter dari params mentah, misal {"date[$gte]": "2024-01-01"}.
+// per_page dipakai sebagai batas jumlah baris yang diambil
+func FilterFromParams(params map[string]any) *Filter {
+	f := NewFilter()
+	for key, value := range params {
+		if key == "per_page" {
+			if n, err := strconv.Atoi(fmt.Sprint(value)); err == nil {
+				f.Limit(n)
+				continue
+			}
+		}
+		f.Set(key, value)
+	}
+	return f
+}
+
+// Set menambahkan query param apa adanya
+func (f *Filter) Set(key string, value any) *Filter {
+	f.values.Set(key, fmt.Sprint(value))
+	return f
+}
+
+func (f *Filter) op(field, op string, value any) *Filter {
+	return f.Set(field+"[$"+op+"]", value)
+}
+
+func (f *Filter) Eq(field string, value any) *Filter    { return f.op(field, "eq", value) }
+func (f *Filter) Ne(field string, value any) *Filter    { return f.op(field, "ne", value) }
+func (f *Filter) Gt(field string, value any) *Filter    { return f.op(field, "gt", value) }
+func (f *Filter) Gte(field string, value any) *Filter   { return f.op(field, "gte", value) }
+func (f *Filter) Lt(field string, value any) *Filter    { return f.op(field, "lt", value) }
+func (f *Filter) Lte(field string, value any) *Filter   { return f.op(field, "lte", value) }
+func (f *Filter) Like(field string, value any) *Filter  { return f.op(field, "like", value) }
+func (f *Filter) ILike(field string, value any) *Filter { return f.op(field, "ilike", value) }
+
+// DateRange filter date[$gte] dan date[$lte] dengan format YYYY-MM-DD, nilai kosong diabaikan
+func (f *Filter) DateRange(from, to string) *Filter {
+	if from != "" {
+		f.Gte("date", from)
+	}
+	if to != "" {
+		f.Lte("date", to)
+	}
+	return f
+}
+
+// Include menambahkan relasi, misal Include("line_items") menjadi includes[line_items]=true
+func (f *Filter) Include(relation string) *Filter {
+	return f.Set("includes["+relation+"]", "true")
+}
+
+// Limit membatasi jumlah baris total dari semua halaman, 0 berarti ambil semua
+func (f *Filter) Limit(n int) *Filter {
+	f.limit = n
+	return f
+}
+
+// Page mengambil satu halaman tertentu saja, tanpa iterasi otomatis
+func (f *Filter) Page(n int) *Filter {
+	return f.Set("page", n)
+}
+
+// Values salinan query param
+func (f *Filter) Values() url.Values {
+	v := url.Values{}
+	if f == nil {
+		return v
+	}
+	for key, vals := range f.values {
+		v[key] = append([]string{}, vals...)
+	}
+	return v
+}
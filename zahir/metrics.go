@@ -0,0 +1,49 @@
+package zahir
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "zai_zahir_requests_total",
+		Help: "Jumlah request ke Zahir API per endpoint dan status HTTP (error untuk kegagalan transport).",
+	}, []string{"method", "endpoint", "status"})
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "zai_zahir_request_duration_seconds",
+		Help:    "Durasi request ke Zahir API dalam detik.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"method", "endpoint"})
+)
+
+// resourceSegment segmen path berupa nama resource, segmen lain (ID, UUID, nomor) dianggap ID
+var resourceSegment = regexp.MustCompile(`^[a-z_]+$`)
+
+// observe mencatat satu request ke Zahir, status 0 berarti request gagal sebelum ada response
+func observe(method, endpoint string, status int, d time.Duration) {
+	ep := metricEndpoint(endpoint)
+	code := "error"
+	if status != 0 {
+		code = strconv.Itoa(status)
+	}
+	requestsTotal.WithLabelValues(method, ep, code).Inc()
+	requestDuration.WithLabelValues(method, ep).Observe(d.Seconds())
+}
+
+// metricEndpoint endpoint tanpa ID, misal contacts/8f1c... menjadi contacts/{id},
+// supaya jumlah kombinasi label metric tetap terbatas
+func metricEndpoint(endpoint string) string {
+	segments := strings.Split(strings.Trim(strings.TrimSpace(endpoint), "/"), "/")
+	for i, s := range segments {
+		if !resourceSegment.MatchString(s) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}